@@ -1,6 +1,9 @@
 package redisc
 
 import (
+	"crypto/tls"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,12 +17,19 @@ import (
 //_______________________________________________________________________
 
 func NewSettings() *Settings {
-	s := &Settings{}
+	s := &Settings{
+		slowLogSize: defaultSlowLogSize, // Retains the most recent slow commands, bounding the memory used.
+	}
 	s.
 		SetRetry(NewRetrySettings()).
 		SetTimeout(NewTimeoutSettings()).
 		SetPool(NewPoolSettings()).
-		SetConn(NewConnSettings())
+		SetConn(NewConnSettings()).
+		SetSentinel(NewSentinelSettings()).
+		SetCluster(NewClusterSettings()).
+		SetTLS(NewTLSSettings()).
+		SetReconnect(NewReconnectSettings()).
+		SetTracking(NewTrackingSettings())
 	return s
 }
 
@@ -61,6 +71,49 @@ func NewPoolSettings() *poolSettings {
 	return p
 }
 
+func NewSentinelSettings() *sentinelSettings {
+	s := &sentinelSettings{
+		enabled: false, // Sentinel mode is opt-in. The address from connectionSettings is used by default.
+	}
+	return s
+}
+
+func NewClusterSettings() *clusterSettings {
+	c := &clusterSettings{
+		enabled:      false, // Cluster mode is opt-in. A single-node client is used by default.
+		maxRedirects: 8,     // Follows up to 8 MOVED/ASK redirects, matching the go-redis default.
+	}
+	return c
+}
+
+func NewTLSSettings() *tlsSettings {
+	t := &tlsSettings{
+		enabled:    false,            // TLS is opt-in. Enable it for managed Redis that requires encrypted connections.
+		minVersion: tls.VersionTLS12, // Rejects legacy protocol versions with known weaknesses.
+	}
+	return t
+}
+
+func NewReconnectSettings() *reconnectSettings {
+	r := &reconnectSettings{
+		initialDelay: 1 * time.Second,  // Waits a second before the second attempt, leaving time for transient failures to clear.
+		multiplier:   2,                // Doubles the delay after each failed attempt.
+		maxDelay:     30 * time.Second, // Caps the delay so that recovery is detected within half a minute.
+		jitter:       0.2,              // Spreads the attempts of many instances by ±20%.
+		maxAttempts:  0,                // Never gives up by default.
+		giveUp:       GiveUpResume,     // Starts a new cycle on the next failed ping if the policy gives up.
+	}
+	return r
+}
+
+func NewTrackingSettings() *trackingSettings {
+	t := &trackingSettings{
+		enabled: false,           // Server-assisted client-side caching is opt-in, as it requires redis 6 or later.
+		mode:    TrackingDefault, // Only tracks the keys actually read, keeping the server-side tracking table small.
+	}
+	return t
+}
+
 // IsEnabled returns true if the configuration is enabled, indicating that
 // a connection to Redis should be attempted.
 func (c *Settings) IsEnabled() bool {
@@ -84,6 +137,31 @@ func (c *Settings) IsPingInterval() bool {
 	return c.keepalive && c.pingInterval != 0
 }
 
+// SlowThreshold returns the duration above which a command is considered slow; zero if disabled.
+func (c *Settings) SlowThreshold() time.Duration {
+	return c.slowThreshold
+}
+
+// SlowLogSize returns the number of slow commands retained in the slow log of the Datasource.
+func (c *Settings) SlowLogSize() int {
+	return c.slowLogSize
+}
+
+// Logger returns the logger receiving the log lines of the Datasource; nil if the default loggy adapter is used.
+func (c *Settings) Logger() Logger {
+	return c.logger
+}
+
+// IsLazy returns true if the initial connection is established in the background.
+func (c *Settings) IsLazy() bool {
+	return c.lazy
+}
+
+// IsTransitionsOnly returns true if the connection events are only published on state transitions.
+func (c *Settings) IsTransitionsOnly() bool {
+	return c.transitionsOnly
+}
+
 func (c *Settings) Conn() *connectionSettings {
 	return c.conn
 }
@@ -100,26 +178,86 @@ func (c *Settings) Pool() *poolSettings {
 	return c.pool
 }
 
-// redis://<username>:<password>@<host>:<port>
+func (c *Settings) Sentinel() *sentinelSettings {
+	return c.sentinel
+}
+
+func (c *Settings) Cluster() *clusterSettings {
+	return c.cluster
+}
+
+// IsCluster returns true if cluster mode is enabled, indicating that a cluster-aware
+// client should be created from the configured seed nodes.
+func (c *Settings) IsCluster() bool {
+	return c.cluster != nil && c.cluster.enabled
+}
+
+func (c *Settings) TLS() *tlsSettings {
+	return c.tls
+}
+
+// IsTLS returns true if TLS is enabled, indicating that connections to the
+// Redis server should be encrypted.
+func (c *Settings) IsTLS() bool {
+	return c.tls != nil && c.tls.enabled
+}
+
+func (c *Settings) Reconnect() *reconnectSettings {
+	return c.reconnect
+}
+
+func (c *Settings) Tracking() *trackingSettings {
+	return c.tracking
+}
+
+// IsTracking returns true if server-assisted client-side caching is enabled, indicating that
+// a CLIENT TRACKING session should be established alongside every connection.
+func (c *Settings) IsTracking() bool {
+	return c.tracking != nil && c.tracking.enabled
+}
+
+// IsSentinel returns true if sentinel mode is enabled, indicating that the master address
+// should be resolved through the configured sentinels.
+func (c *Settings) IsSentinel() bool {
+	return c.sentinel != nil && c.sentinel.enabled
+}
+
+// redis://<username>:<password>@<host>:<port>/<db>?<options>
+// rediss://<username>:<password>@<host>:<port>/<db>?<options> (TLS enabled)
+// unix://<username>:<password>@</path/to/socket>?db=<db>&<options>
+//
+// The database is omitted when it is 0, and the options only list the retry, timeout and pool
+// values that differ from the defaults, so that the result can be parsed back with ParseSettings.
 func (c *Settings) String(safe bool) string {
 	var builder strings.Builder
-	builder.WriteString("redis://")
-	if unify4g.IsEmpty(c.conn.username) && unify4g.IsEmpty(c.conn.password) {
-		builder.WriteString(c.conn.connectionStrings)
-		return builder.String()
-	}
-	if unify4g.IsNotEmpty(c.conn.username) {
-		builder.WriteString(c.conn.username)
+	switch {
+	case c.conn.network == "unix":
+		builder.WriteString("unix://")
+	case c.IsTLS():
+		builder.WriteString("rediss://")
+	default:
+		builder.WriteString("redis://")
 	}
-	if unify4g.IsNotEmpty(c.conn.password) {
-		builder.WriteString(":")
-		if safe {
-			builder.WriteString("*****")
-		} else {
-			builder.WriteString(c.conn.password)
+	if unify4g.IsNotEmpty(c.conn.username) || unify4g.IsNotEmpty(c.conn.password) {
+		builder.WriteString(url.User(c.conn.username).String())
+		if unify4g.IsNotEmpty(c.conn.password) {
+			builder.WriteString(":")
+			if safe {
+				builder.WriteString("*****")
+			} else {
+				builder.WriteString(strings.TrimPrefix(url.UserPassword("", c.conn.password).String(), ":"))
+			}
 		}
 		builder.WriteString("@")
-		builder.WriteString(c.conn.connectionStrings)
+	}
+	builder.WriteString(c.conn.connectionStrings)
+	if c.conn.network != "unix" && c.conn.database != 0 {
+		builder.WriteString("/")
+		builder.WriteString(strconv.Itoa(c.conn.database))
+	}
+	if query := c.query(); len(query) > 0 {
+		builder.WriteString("?")
+		builder.WriteString(query.Encode())
 	}
 	return builder.String()
 }
@@ -129,12 +267,36 @@ func (c *Settings) String(safe bool) string {
 //_______________________________________________________________________
 
 // Conn returns the underlying redis.Client connection instance in a thread-safe manner.
+// In cluster mode, Conn returns nil; use ClusterConn or Client instead.
 func (d *Datasource) Conn() *redis.Client {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 	return d.conn
 }
 
+// ClusterConn returns the underlying redis.ClusterClient connection instance in a thread-safe manner.
+// It returns nil unless cluster mode is enabled.
+func (d *Datasource) ClusterConn() *redis.ClusterClient {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.cluster
+}
+
+// Client returns the active connection as a redis.UniversalClient in a thread-safe manner,
+// regardless of whether the Datasource runs in single-node, sentinel or cluster mode.
+// It returns nil if no connection has been established.
+func (d *Datasource) Client() redis.UniversalClient {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.cluster != nil {
+		return d.cluster
+	}
+	if d.conn != nil {
+		return d.conn
+	}
+	return nil
+}
+
 // Wrap returns the current wrapify.R instance, which encapsulates the connection status,
 // any error messages, and debugging information in a thread-safe manner.
 func (d *Datasource) Wrap() wrapify.R {
@@ -143,6 +305,39 @@ func (d *Datasource) Wrap() wrapify.R {
 	return d.wrap
 }
 
+// Master returns the address of the redis master currently in use in a thread-safe manner.
+// In sentinel mode, this value follows master promotions detected by the keepalive mechanism.
+func (d *Datasource) Master() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.master
+}
+
+// Latency returns the round-trip time of the last successful ping in a thread-safe manner.
+func (d *Datasource) Latency() time.Duration {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.latency
+}
+
+// Primary returns the primary Datasource this Datasource replicates, or nil if it is not a replica.
+func (d *Datasource) Primary() *Datasource {
+	return d.primary
+}
+
+// IsReplica returns true if the Datasource is a replica belonging to a ReplicaSet.
+func (d *Datasource) IsReplica() bool {
+	return d.primary != nil
+}
+
+// ReconnectAttempts returns the number of consecutive failed reconnection attempts in a thread-safe manner.
+// It is reset to 0 once a reconnection succeeds.
+func (d *Datasource) ReconnectAttempts() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.attempts
+}
+
 // Conf returns the Settings configuration associated with the Datasource.
 func (d *Datasource) Conf() Settings {
 	return d.conf
@@ -151,7 +346,28 @@ func (d *Datasource) Conf() Settings {
 // IsConnected returns true if the current wrap indicates a successful connection to redis,
 // otherwise it returns false.
 func (d *Datasource) IsConnected() bool {
-	return d.Wrap().IsSuccess()
+	return !d.IsClosed() && d.Wrap().IsSuccess()
+}
+
+// IsClosed returns true if the Datasource has been closed by Close or Shutdown.
+func (d *Datasource) IsClosed() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.closed
+}
+
+// Logger returns the logger receiving the log lines of the Datasource: the one set by SetLogger,
+// else the one of the Settings, else the default loggy adapter.
+func (d *Datasource) Logger() Logger {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.logger != nil {
+		return d.logger
+	}
+	if d.conf.logger != nil {
+		return d.conf.logger
+	}
+	return loggyLogger{}
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -184,6 +400,49 @@ func (c *Settings) SetKeepalive(value bool) *Settings {
 	return c
 }
 
+// SetSlowThreshold sets the duration above which a command is logged and retained in the slow log
+// (zero disables it) and returns the updated Settings.
+func (c *Settings) SetSlowThreshold(value time.Duration) *Settings {
+	c.slowThreshold = value
+	return c
+}
+
+// SetSlowLogSize sets the number of slow commands retained in the slow log and returns the updated Settings.
+func (c *Settings) SetSlowLogSize(value int) *Settings {
+	c.slowLogSize = value
+	return c
+}
+
+// setStopped safely records whether the keepalive routine has been stopped by the reconnection policy.
+func (d *Datasource) setStopped(value bool) *Datasource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = value
+	return d
+}
+
+// SetLogger sets the logger receiving the log lines of the Datasource (the default loggy adapter if nil)
+// and returns the updated Settings. See NewLoggyLogger and NewSlogLogger.
+func (c *Settings) SetLogger(value Logger) *Settings {
+	c.logger = value
+	return c
+}
+
+// SetLazy enables or disables the background establishment of the initial connection, in which case
+// NewClient returns immediately, and returns the updated Settings.
+func (c *Settings) SetLazy(value bool) *Settings {
+	c.lazy = value
+	return c
+}
+
+// SetTransitionsOnly enables or disables the reporting of state transitions only, in which case no
+// connection event (and thus no on callback) is published for keepalive pings that leave the state
+// unchanged, and returns the updated Settings.
+func (c *Settings) SetTransitionsOnly(value bool) *Settings {
+	c.transitionsOnly = value
+	return c
+}
+
 func (c *Settings) SetConn(value *connectionSettings) *Settings {
 	if value == nil {
 		value = NewConnSettings()
@@ -216,6 +475,46 @@ func (c *Settings) SetPool(value *poolSettings) *Settings {
 	return c
 }
 
+func (c *Settings) SetSentinel(value *sentinelSettings) *Settings {
+	if value == nil {
+		value = NewSentinelSettings()
+	}
+	c.sentinel = value
+	return c
+}
+
+func (c *Settings) SetCluster(value *clusterSettings) *Settings {
+	if value == nil {
+		value = NewClusterSettings()
+	}
+	c.cluster = value
+	return c
+}
+
+func (c *Settings) SetTLS(value *tlsSettings) *Settings {
+	if value == nil {
+		value = NewTLSSettings()
+	}
+	c.tls = value
+	return c
+}
+
+func (c *Settings) SetReconnect(value *reconnectSettings) *Settings {
+	if value == nil {
+		value = NewReconnectSettings()
+	}
+	c.reconnect = value
+	return c
+}
+
+func (c *Settings) SetTracking(value *trackingSettings) *Settings {
+	if value == nil {
+		value = NewTrackingSettings()
+	}
+	c.tracking = value
+	return c
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Setter connectionSettings
 //_______________________________________________________________________
@@ -317,6 +616,166 @@ func (p *poolSettings) SetIdleCheckFrequency(value time.Duration) *poolSettings
 	return p
 }
 
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter sentinelSettings
+//_______________________________________________________________________
+
+func (s *sentinelSettings) SetEnable(value bool) *sentinelSettings {
+	s.enabled = value
+	return s
+}
+
+func (s *sentinelSettings) SetMasterName(value string) *sentinelSettings {
+	s.masterName = value
+	return s
+}
+
+func (s *sentinelSettings) SetAddrs(values []string) *sentinelSettings {
+	s.addrs = values
+	return s
+}
+
+func (s *sentinelSettings) AppendAddrs(values ...string) *sentinelSettings {
+	s.addrs = append(s.addrs, values...)
+	return s
+}
+
+func (s *sentinelSettings) SetPassword(value string) *sentinelSettings {
+	s.password = value
+	return s
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter clusterSettings
+//_______________________________________________________________________
+
+func (c *clusterSettings) SetEnable(value bool) *clusterSettings {
+	c.enabled = value
+	return c
+}
+
+func (c *clusterSettings) SetAddrs(values []string) *clusterSettings {
+	c.addrs = values
+	return c
+}
+
+func (c *clusterSettings) AppendAddrs(values ...string) *clusterSettings {
+	c.addrs = append(c.addrs, values...)
+	return c
+}
+
+func (c *clusterSettings) SetReadOnly(value bool) *clusterSettings {
+	c.readOnly = value
+	return c
+}
+
+func (c *clusterSettings) SetRouteByLatency(value bool) *clusterSettings {
+	c.routeByLatency = value
+	return c
+}
+
+func (c *clusterSettings) SetRouteRandomly(value bool) *clusterSettings {
+	c.routeRandomly = value
+	return c
+}
+
+func (c *clusterSettings) SetMaxRedirects(value int) *clusterSettings {
+	c.maxRedirects = value
+	return c
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter tlsSettings
+//_______________________________________________________________________
+
+func (t *tlsSettings) SetEnable(value bool) *tlsSettings {
+	t.enabled = value
+	return t
+}
+
+func (t *tlsSettings) SetCAFile(value string) *tlsSettings {
+	t.caFile = value
+	return t
+}
+
+func (t *tlsSettings) SetCertFile(value string) *tlsSettings {
+	t.certFile = value
+	return t
+}
+
+func (t *tlsSettings) SetKeyFile(value string) *tlsSettings {
+	t.keyFile = value
+	return t
+}
+
+func (t *tlsSettings) SetServerName(value string) *tlsSettings {
+	t.serverName = value
+	return t
+}
+
+func (t *tlsSettings) SetInsecureSkipVerify(value bool) *tlsSettings {
+	t.insecureSkipVerify = value
+	return t
+}
+
+func (t *tlsSettings) SetMinVersion(value uint16) *tlsSettings {
+	t.minVersion = value
+	return t
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter reconnectSettings
+//_______________________________________________________________________
+
+func (r *reconnectSettings) SetInitialDelay(value time.Duration) *reconnectSettings {
+	r.initialDelay = value
+	return r
+}
+
+func (r *reconnectSettings) SetMultiplier(value float64) *reconnectSettings {
+	r.multiplier = value
+	return r
+}
+
+func (r *reconnectSettings) SetMaxDelay(value time.Duration) *reconnectSettings {
+	r.maxDelay = value
+	return r
+}
+
+func (r *reconnectSettings) SetJitter(value float64) *reconnectSettings {
+	r.jitter = value
+	return r
+}
+
+func (r *reconnectSettings) SetMaxAttempts(value int) *reconnectSettings {
+	r.maxAttempts = value
+	return r
+}
+
+func (r *reconnectSettings) SetGiveUp(value GiveUpAction) *reconnectSettings {
+	r.giveUp = value
+	return r
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter trackingSettings
+//_______________________________________________________________________
+
+func (t *trackingSettings) SetEnable(value bool) *trackingSettings {
+	t.enabled = value
+	return t
+}
+
+func (t *trackingSettings) SetMode(value TrackingMode) *trackingSettings {
+	t.mode = value
+	return t
+}
+
+func (t *trackingSettings) SetPrefixes(values ...string) *trackingSettings {
+	t.prefixes = values
+	return t
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Setter Datasource
 //_______________________________________________________________________
@@ -330,6 +789,40 @@ func (d *Datasource) SetConn(value *redis.Client) *Datasource {
 	return d
 }
 
+// SetMaster safely updates the address of the redis master currently in use
+// and returns the updated Datasource for method chaining.
+func (d *Datasource) SetMaster(value string) *Datasource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.master = value
+	return d
+}
+
+// setLatency safely updates the round-trip time of the last successful ping.
+func (d *Datasource) setLatency(value time.Duration) *Datasource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.latency = value
+	return d
+}
+
+// setAttempts safely updates the number of consecutive failed reconnection attempts.
+func (d *Datasource) setAttempts(value int) *Datasource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attempts = value
+	return d
+}
+
+// SetLogger sets the logger receiving the log lines of the Datasource, taking precedence over the logger
+// of the Settings, and returns the updated Datasource.
+func (d *Datasource) SetLogger(value Logger) *Datasource {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.logger = value
+	return d
+}
+
 // SetWrap safely updates the wrapify.R instance (which holds connection status and error info)
 // of the Datasource and returns the updated Datasource.
 func (d *Datasource) SetWrap(value wrapify.R) *Datasource {
@@ -340,11 +833,21 @@ func (d *Datasource) SetWrap(value wrapify.R) *Datasource {
 }
 
 // SetOn sets the callback function that is invoked upon connection state changes (e.g., during keepalive events)
-// and returns the updated Datasource for method chaining.
+// and returns the updated Datasource for method chaining. It is a shorthand for a Subscription to the
+// EventPingOK, EventPingFailed, EventReconnected and EventReconnectFailed events that replaces the one
+// registered by the previous call; use Subscribe to register several handlers.
 func (d *Datasource) SetOn(fnc func(response wrapify.R)) *Datasource {
+	var s *Subscription
+	if fnc != nil {
+		s = d.Subscribe(func(event Event) {
+			fnc(event.Response)
+		}, EventPingOK, EventPingFailed, EventReconnected, EventReconnectFailed)
+	}
 	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.on = fnc
+	previous := d.on
+	d.on = s
+	d.mu.Unlock()
+	previous.Unsubscribe()
 	return d
 }
 
@@ -352,23 +855,197 @@ func (d *Datasource) SetOn(fnc func(response wrapify.R)) *Datasource {
 // such as replica failovers, reconnection attempts, or health status updates.
 // This function accepts a callback that receives both the current status (encapsulated in wrapify.R)
 // and a pointer to the Datasource representing the replica connection (replicator), allowing external
-// components to implement custom logic for replica management. The updated Datasource instance is returned
-// to support method chaining.
+// components to implement custom logic for replica management. It is a shorthand for a Subscription to the
+// EventReplicaChanged events that replaces the one registered by the previous call. The updated Datasource
+// instance is returned to support method chaining.
 func (d *Datasource) SetOnReplica(fnc func(response wrapify.R, replicator *Datasource)) *Datasource {
+	var s *Subscription
+	if fnc != nil {
+		s = d.Subscribe(func(event Event) {
+			fnc(event.Response, event.Replica)
+		}, EventReplicaChanged)
+	}
 	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.onReplica = fnc
+	previous := d.onReplica
+	d.onReplica = s
+	d.mu.Unlock()
+	previous.Unsubscribe()
 	return d
 }
 
 // SetNotifier sets the callback function that is invoked for significant datasource events,
-// such as reconnection attempts, keepalive signals, or other diagnostic updates.
-// This function stores the provided notifier, which can be used to asynchronously notify
-// external components of changes in the connection's status, and returns the updated Datasource instance
-// to support method chaining.
+// such as command errors or a reconnection policy giving up. It is a shorthand for a Subscription to the
+// EventCommandError and EventReconnectGaveUp events that replaces the one registered by the previous call,
+// and returns the updated Datasource instance to support method chaining.
 func (d *Datasource) SetNotifier(fnc func(response wrapify.R)) *Datasource {
+	var s *Subscription
+	if fnc != nil {
+		s = d.Subscribe(func(event Event) {
+			fnc(event.Response)
+		}, EventCommandError, EventReconnectGaveUp)
+	}
 	d.mu.Lock()
-	defer d.mu.Unlock()
-	d.notifier = fnc
+	previous := d.notifier
+	d.notifier = s
+	d.mu.Unlock()
+	previous.Unsubscribe()
 	return d
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Getter ReplicaSet
+//_______________________________________________________________________
+
+// Primary returns the primary Datasource of the ReplicaSet.
+func (r *ReplicaSet) Primary() *Datasource {
+	return r.primary
+}
+
+// Replicas returns the replica Datasources of the ReplicaSet.
+func (r *ReplicaSet) Replicas() []*Datasource {
+	return r.replicas
+}
+
+// Strategy returns the strategy used to pick a replica for read-only commands.
+func (r *ReplicaSet) Strategy() ReplicaStrategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.strategy
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter ReplicaSet
+//_______________________________________________________________________
+
+// SetStrategy sets the strategy used to pick a replica for read-only commands
+// and returns the updated ReplicaSet for method chaining.
+func (r *ReplicaSet) SetStrategy(value ReplicaStrategy) *ReplicaSet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategy = value
+	return r
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Getter Collector
+//_______________________________________________________________________
+
+// Namespace returns the prefix of the metric names rendered by the Collector.
+func (c *Collector) Namespace() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.namespace
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter Collector
+//_______________________________________________________________________
+
+// SetNamespace sets the prefix of the metric names rendered by the Collector (none if empty)
+// and returns the updated Collector.
+func (c *Collector) SetNamespace(value string) *Collector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.namespace = value
+	return c
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter TracingHook
+//_______________________________________________________________________
+
+// SetRedactor sets the function rendering the db.statement attribute of the spans (RedactArgs if nil)
+// and returns the updated TracingHook.
+func (t *TracingHook) SetRedactor(value Redactor) *TracingHook {
+	if value == nil {
+		value = RedactArgs
+	}
+	t.redactor = value
+	return t
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Getter Cache
+//_______________________________________________________________________
+
+// Datasource returns the Datasource the values of the Cache are stored in.
+func (c *Cache[T]) Datasource() *Datasource {
+	return c.datasource
+}
+
+// Codec returns the codec converting the values of the Cache to and from bytes.
+func (c *Cache[T]) Codec() Codec {
+	return c.codec
+}
+
+// Prefix returns the prefix prepended to every key of the Cache.
+func (c *Cache[T]) Prefix() string {
+	return c.prefix
+}
+
+// StaleTTL returns the duration an expired value is still served by GetOrLoad while it is refreshed.
+func (c *Cache[T]) StaleTTL() time.Duration {
+	return c.staleTTL
+}
+
+// LockTTL returns the expiration of the lock taken by GetOrLoad before loading a value.
+func (c *Cache[T]) LockTTL() time.Duration {
+	return c.lockTTL
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter Cache
+//_______________________________________________________________________
+
+// SetPrefix sets the prefix prepended to every key of the Cache, e.g. "users:", and returns the updated Cache.
+func (c *Cache[T]) SetPrefix(value string) *Cache[T] {
+	c.prefix = value
+	return c
+}
+
+// SetStaleTTL sets the duration an expired value is still served by GetOrLoad while it is refreshed
+// in the background (stale-while-revalidate), 0 to disable, and returns the updated Cache.
+func (c *Cache[T]) SetStaleTTL(value time.Duration) *Cache[T] {
+	c.staleTTL = value
+	return c
+}
+
+// SetLockTTL sets the expiration of the lock taken by GetOrLoad before loading a value, so that a single
+// instance loads it at a time across the fleet, 0 to disable, and returns the updated Cache.
+func (c *Cache[T]) SetLockTTL(value time.Duration) *Cache[T] {
+	c.lockTTL = value
+	return c
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Getter NearCache
+//_______________________________________________________________________
+
+// Cache returns the Cache the values of the NearCache are read from and written through to.
+func (n *NearCache[T]) Cache() *Cache[T] {
+	return n.cache
+}
+
+// Channel returns the pub/sub channel the invalidation messages of the NearCache are broadcast on,
+// unused when they are pushed by the server.
+func (n *NearCache[T]) Channel() string {
+	return n.channel
+}
+
+// Policy returns the eviction policy of the in-process entries.
+func (n *NearCache[T]) Policy() EvictionPolicy {
+	n.local.mu.Lock()
+	defer n.local.mu.Unlock()
+	return n.local.policy
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Setter NearCache
+//_______________________________________________________________________
+
+// SetPolicy sets the eviction policy of the in-process entries, EvictLRU or EvictLFU, and returns
+// the updated NearCache.
+func (n *NearCache[T]) SetPolicy(value EvictionPolicy) *NearCache[T] {
+	n.local.setPolicy(value)
+	return n
+}
@@ -0,0 +1,305 @@
+package redisc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/wrapify"
+)
+
+// NewCache creates a Cache storing values of type T in the Datasource, converted with the given codec
+// (JSONCodec if nil). Every operation reports its outcome as a wrapify.R, in the same way as AllKeys:
+// a closed or disconnected Datasource yields its current status, and the failures of the commands are
+// published as EventCommandError events, unlike the values that the codec fails to encode or decode.
+func NewCache[T any](d *Datasource, codec Codec) *Cache[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &Cache[T]{datasource: d, codec: codec, flights: &flightGroup[T]{calls: make(map[string]*flightCall[T])}}
+}
+
+// Get retrieves the value stored under key.
+// It is equivalent to GetContext with a background context.
+func (c *Cache[T]) Get(key string) (T, wrapify.R) {
+	return c.GetContext(context.Background(), key)
+}
+
+// GetContext retrieves the value stored under key, giving up as soon as ctx expires or is cancelled.
+//
+// Returns:
+//   - the value and a 200 response carrying it if the key exists;
+//   - the zero value and a 404 response if the key does not exist;
+//   - the zero value and an error response otherwise.
+func (c *Cache[T]) GetContext(ctx context.Context, key string) (T, wrapify.R) {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		var value T
+		return value, response
+	}
+	return c.get(ctx, client, key)
+}
+
+// get retrieves the value stored under key through the given client, see GetContext.
+func (c *Cache[T]) get(ctx context.Context, client redis.UniversalClient, key string) (T, wrapify.R) {
+	var value T
+	var data []byte
+	err := await(ctx, func() error {
+		var err error
+		data, err = client.Get(c.key(key)).Bytes()
+		return err
+	})
+	if err == redis.Nil {
+		return value, wrapify.WrapNotFound("", nil).
+			WithMessagef("The key '%s' does not exist", key).
+			WithDebuggingKV("function", "cache_get").
+			WithHeader(wrapify.NotFound).
+			Reply()
+	}
+	if err != nil {
+		return value, c.fail(ctx, err, "cache_get", key, "Failed to retrieve the key '%s'")
+	}
+	if err := c.codec.Unmarshal(data, &value); err != nil {
+		return value, c.failure(ctx, err, "cache_get", key, "Failed to decode the value of the key '%s'")
+	}
+	return value, wrapify.WrapOk("", value).
+		WithMessagef("Successfully retrieved the key '%s'", key).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Set stores the value under key, expiring after ttl (never if ttl is zero), tagged with the given tags.
+// It is equivalent to SetContext with a background context.
+func (c *Cache[T]) Set(key string, value T, ttl time.Duration, tags ...string) wrapify.R {
+	return c.SetContext(context.Background(), key, value, ttl, tags...)
+}
+
+// SetContext stores the value under key, expiring after ttl (never if ttl is zero), giving up as soon
+// as ctx expires or is cancelled. The key is recorded as a member of every given tag, so that it can be
+// removed along with the other keys of the tag by InvalidateTags; see InvalidateTagsContext.
+func (c *Cache[T]) SetContext(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) wrapify.R {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return response
+	}
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return c.failure(ctx, err, "cache_set", key, "Failed to encode the value of the key '%s'")
+	}
+	if len(tags) > 0 {
+		return c.setTagged(ctx, client, key, data, ttl, tags)
+	}
+	if err := await(ctx, func() error { return client.Set(c.key(key), data, ttl).Err() }); err != nil {
+		return c.fail(ctx, err, "cache_set", key, "Failed to store the key '%s'")
+	}
+	return wrapify.WrapOk("", nil).
+		WithMessagef("Successfully stored the key '%s'", key).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Delete removes the given keys.
+// It is equivalent to DeleteContext with a background context.
+func (c *Cache[T]) Delete(keys ...string) wrapify.R {
+	return c.DeleteContext(context.Background(), keys...)
+}
+
+// DeleteContext removes the given keys, giving up as soon as ctx expires or is cancelled. The keys are
+// removed by a pipeline of one DEL command per key, as they may belong to different hash slots in cluster
+// mode. The body of the response holds the number of keys removed.
+func (c *Cache[T]) DeleteContext(ctx context.Context, keys ...string) wrapify.R {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return response
+	}
+	var deleted int64
+	err := await(ctx, func() error {
+		cmds, err := client.Pipelined(func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(c.key(key))
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if n, ok := cmd.(*redis.IntCmd); ok {
+				deleted += n.Val()
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return c.fail(ctx, err, "cache_delete", "", "Failed to remove the keys")
+	}
+	return wrapify.WrapOk("", deleted).
+		WithMessagef("Successfully removed %d of %d keys", deleted, len(keys)).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Exists reports whether a value is stored under key.
+// It is equivalent to ExistsContext with a background context.
+func (c *Cache[T]) Exists(key string) (bool, wrapify.R) {
+	return c.ExistsContext(context.Background(), key)
+}
+
+// ExistsContext reports whether a value is stored under key, giving up as soon as ctx expires or is cancelled.
+func (c *Cache[T]) ExistsContext(ctx context.Context, key string) (bool, wrapify.R) {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return false, response
+	}
+	var n int64
+	err := await(ctx, func() error {
+		var err error
+		n, err = client.Exists(c.key(key)).Result()
+		return err
+	})
+	if err != nil {
+		return false, c.fail(ctx, err, "cache_exists", key, "Failed to check the existence of the key '%s'")
+	}
+	return n > 0, wrapify.WrapOk("", n > 0).
+		WithMessagef("Successfully checked the existence of the key '%s'", key).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// MGet retrieves the values stored under the given keys.
+// It is equivalent to MGetContext with a background context.
+func (c *Cache[T]) MGet(keys ...string) (map[string]T, wrapify.R) {
+	return c.MGetContext(context.Background(), keys...)
+}
+
+// MGetContext retrieves the values stored under the given keys in a single round trip (one per node
+// in cluster mode), giving up as soon as ctx expires or is cancelled. The keys that do not exist are
+// absent from the returned map.
+func (c *Cache[T]) MGetContext(ctx context.Context, keys ...string) (map[string]T, wrapify.R) {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return nil, response
+	}
+	var cmds []*redis.StringCmd
+	err := await(ctx, func() error {
+		_, err := client.Pipelined(func(pipe redis.Pipeliner) error {
+			cmds = make([]*redis.StringCmd, len(keys))
+			for i, key := range keys {
+				cmds[i] = pipe.Get(c.key(key))
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil && err != redis.Nil {
+		return nil, c.fail(ctx, err, "cache_mget", "", "Failed to retrieve the keys")
+	}
+	values := make(map[string]T, len(keys))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, c.fail(ctx, err, "cache_mget", keys[i], "Failed to retrieve the key '%s'")
+		}
+		var value T
+		if err := c.codec.Unmarshal(data, &value); err != nil {
+			return nil, c.failure(ctx, err, "cache_mget", keys[i], "Failed to decode the value of the key '%s'")
+		}
+		values[keys[i]] = value
+	}
+	return values, wrapify.WrapOk("", values).
+		WithMessagef("Successfully retrieved %d of %d keys", len(values), len(keys)).
+		WithTotal(len(values)).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// MSet stores the given values, each expiring after ttl (never if ttl is zero).
+// It is equivalent to MSetContext with a background context.
+func (c *Cache[T]) MSet(values map[string]T, ttl time.Duration) wrapify.R {
+	return c.MSetContext(context.Background(), values, ttl)
+}
+
+// MSetContext stores the given values, each expiring after ttl (never if ttl is zero), in a single round
+// trip (one per node in cluster mode), giving up as soon as ctx expires or is cancelled. Unlike the MSET
+// command, the values are not stored atomically.
+func (c *Cache[T]) MSetContext(ctx context.Context, values map[string]T, ttl time.Duration) wrapify.R {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return response
+	}
+	encoded := make(map[string][]byte, len(values))
+	for key, value := range values {
+		data, err := c.codec.Marshal(value)
+		if err != nil {
+			return c.failure(ctx, err, "cache_mset", key, "Failed to encode the value of the key '%s'")
+		}
+		encoded[c.key(key)] = data
+	}
+	err := await(ctx, func() error {
+		_, err := client.Pipelined(func(pipe redis.Pipeliner) error {
+			for key, data := range encoded {
+				pipe.Set(key, data, ttl)
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return c.fail(ctx, err, "cache_mset", "", "Failed to store the keys")
+	}
+	return wrapify.WrapOk("", nil).
+		WithMessagef("Successfully stored %d keys", len(values)).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// key returns the redis key under which the value of key is stored.
+func (c *Cache[T]) key(key string) string {
+	return c.prefix + key
+}
+
+// client returns the client of the Datasource, bound to ctx, if it is ready to serve commands.
+//
+// Returns:
+//   - the client, an empty response and true if the Datasource is connected;
+//   - nil, the current status of the Datasource and false otherwise.
+func (c *Cache[T]) client(ctx context.Context) (redis.UniversalClient, wrapify.R, bool) {
+	d := c.datasource
+	if d.IsClosed() {
+		return nil, closedResponse(), false
+	}
+	client := d.Client()
+	if client == nil || !d.IsConnected() {
+		return nil, d.Wrap(), false
+	}
+	return d.bind(ctx, client), wrapify.R{}, true
+}
+
+// fail builds the response of a failed command, as failure does, and publishes it as an EventCommandError event.
+func (c *Cache[T]) fail(ctx context.Context, err error, function, key, format string) wrapify.R {
+	response := c.failure(ctx, err, function, key, format)
+	c.datasource.notify(EventCommandError, response)
+	return response
+}
+
+// failure builds the response of a failed operation, a request timeout if ctx is done. The message format
+// receives the key, when not empty. Unlike fail, it publishes no event, e.g. for the values that the codec
+// fails to encode or decode, which are not command errors.
+func (c *Cache[T]) failure(ctx context.Context, err error, function, key, format string) wrapify.R {
+	var response wrapify.R
+	if ctx.Err() != nil {
+		response = cancelledResponse(ctx.Err(), "The cache operation has been cancelled")
+	} else {
+		builder := wrapify.WrapInternalServerError("", nil).
+			WithDebuggingKV("function", function).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError)
+		if key != "" {
+			builder = builder.WithMessagef(format, key).WithDebuggingKV("key", key)
+		} else {
+			builder = builder.WithMessage(format)
+		}
+		response = builder.Reply()
+	}
+	return response
+}
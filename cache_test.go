@@ -0,0 +1,30 @@
+package redisc
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCacheCodecFailuresPublishNoEvent(t *testing.T) {
+	s := newFakeServer(t)
+	d := newFakeDatasource(t, s)
+	var events int32
+	d.Subscribe(func(event Event) { atomic.AddInt32(&events, 1) }, EventCommandError)
+	s.Set("n", "not json", 0)
+
+	if _, response := NewCache[int](d, nil).Get("n"); response.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("Get() = %d for an undecodable value, want %d", response.StatusCode(), http.StatusInternalServerError)
+	}
+	if response := NewCache[int](d, RawCodec{}).Set("n", 1, 0); response.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("Set() = %d for an unencodable value, want %d", response.StatusCode(), http.StatusInternalServerError)
+	}
+	s.Fail("GET", "ERR failure")
+	if _, response := NewCache[int](d, nil).Get("n"); response.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("Get() = %d for a failing command, want %d", response.StatusCode(), http.StatusInternalServerError)
+	}
+	d.Close()
+	if got := atomic.LoadInt32(&events); got != 1 {
+		t.Errorf("%d EventCommandError published, want 1 for the failing command only", got)
+	}
+}
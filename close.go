@@ -0,0 +1,110 @@
+package redisc
+
+import (
+	"context"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// Close stops the keepalive routine, waits for the pending callbacks to complete and closes
+// the underlying connection. It is equivalent to Shutdown without a deadline.
+//
+// Returns:
+//   - nil if the Datasource was closed successfully or was already closed;
+//   - the error reported when closing the connection.
+func (d *Datasource) Close() error {
+	return d.Shutdown(context.Background())
+}
+
+// Shutdown gracefully closes the Datasource. It stops the keepalive routine, closes every Subscription
+// and drains the events already queued for them, then closes the underlying connection. If ctx expires before
+// the routine and callbacks complete, the connection is closed anyway and the context error is returned.
+// After Shutdown, every operation returns a "datasource closed" response. Calling Shutdown more than
+// once has no effect.
+//
+// Returns:
+//   - nil if the Datasource was closed successfully or was already closed;
+//   - the context error if ctx expired while waiting, or the error reported when closing the connection.
+func (d *Datasource) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	if d.done != nil {
+		close(d.done)
+	}
+	d.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.routines.Wait()
+		// No event is published once the keepalive routine has stopped, so the subscriptions
+		// can be closed and their queued events drained.
+		d.events.close()
+		d.callbacks.Wait()
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		d.events.close()
+	}
+
+	d.mu.Lock()
+	conn, cluster := d.conn, d.cluster
+	d.wrap = closedResponse()
+	d.transitionLocked(StateClosed, d.wrap.Message())
+	d.mu.Unlock()
+	if conn != nil {
+		if e := conn.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	if cluster != nil {
+		if e := cluster.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	d.untrack()
+	return err
+}
+
+// Close closes every replica, then the primary Datasource of the ReplicaSet.
+//
+// Returns:
+//   - nil if every Datasource was closed successfully;
+//   - the first error reported while closing.
+func (r *ReplicaSet) Close() error {
+	return r.Shutdown(context.Background())
+}
+
+// Shutdown gracefully closes every replica, then the primary Datasource of the ReplicaSet,
+// sharing the deadline of ctx between them.
+//
+// Returns:
+//   - nil if every Datasource was closed successfully;
+//   - the first error reported while closing.
+func (r *ReplicaSet) Shutdown(ctx context.Context) error {
+	var err error
+	for _, replica := range r.replicas {
+		if e := replica.Shutdown(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+	if e := r.primary.Shutdown(ctx); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// closedResponse returns the response reported by every operation attempted on a closed Datasource.
+func closedResponse() wrapify.R {
+	return wrapify.WrapServiceUnavailable("The redis datasource is closed", nil).
+		WithErrSck(errDatasourceClosed).
+		WithHeader(wrapify.ServiceUnavailable).
+		Reply()
+}
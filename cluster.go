@@ -0,0 +1,105 @@
+package redisc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// getClusterOptions builds the redis.ClusterOptions from the current configuration.
+// The seed nodes and routing options come from clusterSettings, while the authentication,
+// retry, timeout and pool options are shared with the single-node client.
+// Note that the pool options apply per cluster node and not for the whole cluster.
+//
+// Returns:
+//   - the options ready to be used by redis.NewClusterClient;
+//   - an error if the TLS configuration cannot be loaded.
+func (d *Datasource) getClusterOptions() (*redis.ClusterOptions, error) {
+	tlsConf, err := d.conf.tls.config()
+	if err != nil {
+		return nil, err
+	}
+	ops := &redis.ClusterOptions{
+		Addrs:              d.conf.cluster.addrs,
+		MaxRedirects:       d.conf.cluster.maxRedirects,
+		ReadOnly:           d.conf.cluster.readOnly,
+		RouteByLatency:     d.conf.cluster.routeByLatency,
+		RouteRandomly:      d.conf.cluster.routeRandomly,
+		Password:           d.conf.conn.password,
+		MaxRetries:         d.conf.retry.maxRetries,
+		MinRetryBackoff:    d.conf.retry.minRetryBackoff,
+		MaxRetryBackoff:    d.conf.retry.maxRetryBackoff,
+		DialTimeout:        d.conf.timeout.connTimeout,
+		ReadTimeout:        d.conf.timeout.readTimeout,
+		WriteTimeout:       d.conf.timeout.writeTimeout,
+		PoolSize:           d.conf.pool.poolSize,
+		MinIdleConns:       d.conf.pool.minIdleConn,
+		MaxConnAge:         d.conf.pool.maxConnAge,
+		PoolTimeout:        d.conf.pool.poolTimeout,
+		IdleTimeout:        d.conf.pool.idleTimeout,
+		IdleCheckFrequency: d.conf.pool.idleCheckFrequency,
+		TLSConfig:          tlsConf,
+		OnNewNode:          d.instrumentNode,
+	}
+	return ops, nil
+}
+
+// reconnectCluster attempts to establish a new connection to the redis cluster using the current configuration,
+// giving up as soon as ctx expires or is cancelled.
+// If every master node of the new connection answers a ping, it replaces the existing cluster connection
+// in the Datasource. In the event that a previous cluster connection exists, it is closed to release
+// associated resources.
+//
+// Returns:
+//   - nil if reconnection is successful;
+//   - an error if no seed node is configured or any master node is unreachable.
+func (d *Datasource) reconnectCluster(ctx context.Context) error {
+	ops, err := d.getClusterOptions()
+	if err != nil {
+		return err
+	}
+	if len(ops.Addrs) == 0 {
+		return fmt.Errorf("at least one redis cluster seed address is required")
+	}
+	current := redis.NewClusterClient(ops)
+	d.instrument(current)
+	ps := time.Now()
+	if err := await(ctx, func() error { return pingCluster(current) }); err != nil {
+		current.Close()
+		return err
+	}
+	latency := time.Since(ps)
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		current.Close()
+		return errDatasourceClosed
+	}
+	previous := d.cluster
+	d.cluster = current
+	d.master = strings.Join(ops.Addrs, ",")
+	d.latency = latency
+	d.mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// pingCluster performs a health check on every master node of the given cluster connection.
+//
+// Returns:
+//   - nil if every master node is healthy;
+//   - the first error reported by a master node, or an error if the topology could not be loaded.
+func pingCluster(cluster *redis.ClusterClient) error {
+	return cluster.ForEachMaster(func(client *redis.Client) error {
+		if err := client.Ping().Err(); err != nil {
+			return fmt.Errorf("redis cluster node '%s': %v", client.Options().Addr, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package redisc
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON data into the value pointed to by v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Marshal encodes v with encoding/gob.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the gob data into the value pointed to by v.
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Marshal returns v as is if it is a []byte, or its bytes if it is a string.
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case []byte:
+		return value, nil
+	case string:
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("the raw codec only supports []byte and string values, not %T", v)
+}
+
+// Unmarshal copies data into the value pointed to by v, which must be a *[]byte or a *string.
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch value := v.(type) {
+	case *[]byte:
+		*value = append([]byte(nil), data...)
+		return nil
+	case *string:
+		*value = string(data)
+		return nil
+	}
+	return fmt.Errorf("the raw codec only supports *[]byte and *string values, not %T", v)
+}
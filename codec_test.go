@@ -0,0 +1,70 @@
+package redisc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type codecUser struct {
+	Name  string
+	Age   int
+	Roles []string
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec Codec
+		value interface{}
+		into  func() interface{}
+	}{
+		{"json struct", JSONCodec{}, codecUser{"ada", 36, []string{"admin"}}, func() interface{} { return new(codecUser) }},
+		{"json map", JSONCodec{}, map[string]int{"a": 1}, func() interface{} { return new(map[string]int) }},
+		{"gob struct", GobCodec{}, codecUser{"ada", 36, []string{"admin"}}, func() interface{} { return new(codecUser) }},
+		{"gob slice", GobCodec{}, []int{1, 2, 3}, func() interface{} { return new([]int) }},
+		{"raw string", RawCodec{}, "hello", func() interface{} { return new(string) }},
+		{"raw bytes", RawCodec{}, []byte{0, 1, 2}, func() interface{} { return new([]byte) }},
+	}
+	for _, tt := range tests {
+		data, err := tt.codec.Marshal(tt.value)
+		if err != nil {
+			t.Errorf("%s: Marshal() error = %v", tt.name, err)
+			continue
+		}
+		into := tt.into()
+		if err := tt.codec.Unmarshal(data, into); err != nil {
+			t.Errorf("%s: Unmarshal() error = %v", tt.name, err)
+			continue
+		}
+		if got := reflect.ValueOf(into).Elem().Interface(); !reflect.DeepEqual(got, tt.value) {
+			t.Errorf("%s: round-trip = %#v, want %#v", tt.name, got, tt.value)
+		}
+	}
+}
+
+func TestRawCodecCopies(t *testing.T) {
+	data := []byte("abc")
+	var decoded []byte
+	if err := (RawCodec{}).Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	data[0] = 'x'
+	if string(decoded) != "abc" {
+		t.Errorf("Unmarshal() = %q, shares the data decoded", decoded)
+	}
+}
+
+func TestRawCodecTypeMismatch(t *testing.T) {
+	if _, err := (RawCodec{}).Marshal(42); err == nil || !strings.Contains(err.Error(), "not int") {
+		t.Errorf("Marshal(42) error = %v, want a type mismatch", err)
+	}
+	var n int
+	if err := (RawCodec{}).Unmarshal([]byte("42"), &n); err == nil || !strings.Contains(err.Error(), "not *int") {
+		t.Errorf("Unmarshal(*int) error = %v, want a type mismatch", err)
+	}
+	var s string
+	if err := (RawCodec{}).Unmarshal([]byte("42"), s); err == nil || !strings.Contains(err.Error(), "not string") {
+		t.Errorf("Unmarshal(string) error = %v, want a type mismatch", err)
+	}
+}
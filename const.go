@@ -1,9 +1,138 @@
 package redisc
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 const (
 	// defaultPingInterval defines the frequency at which the connection is pinged.
 	defaultPingInterval = 30 * time.Second
 	defaultTimeFormat   = "2006-01-02 15:04:05.000000"
+	// defaultStateHistorySize defines the number of state transitions kept by a Datasource.
+	defaultStateHistorySize = 32
+	// defaultEventBufferSize defines the number of events a subscription buffers before dropping them.
+	defaultEventBufferSize = 64
+	// defaultSlowLogSize defines the number of slow commands retained by a Datasource.
+	defaultSlowLogSize = 128
+	// defaultMetricsNamespace defines the prefix of the metric names rendered by a Collector.
+	defaultMetricsNamespace = "redisc"
+	// defaultLockPollInterval defines the frequency at which GetOrLoad checks whether the value locked
+	// by another instance has been loaded.
+	defaultLockPollInterval = 25 * time.Millisecond
+	// defaultInvalidationChannel defines the pub/sub channel the invalidation messages of a NearCache are
+	// broadcast on, suffixed with the prefix of its Cache.
+	defaultInvalidationChannel = "redisc:invalidate:"
+	// defaultResubscribeInterval defines the frequency at which a NearCache retries to subscribe to its
+	// invalidation channel, and checks whether the Datasource has switched to a new client.
+	defaultResubscribeInterval = time.Second
+	// defaultLFUAgingPeriod defines the number of hits, per entry of capacity, after which the hit counts
+	// of the entries of a NearCache evicting with EvictLFU are halved, so that formerly popular entries
+	// eventually make room for the new ones.
+	defaultLFUAgingPeriod = 10
+	// defaultTagKeyPrefix defines the prefix of the sorted sets recording the keys of every tag of a Cache.
+	defaultTagKeyPrefix = "redisc:tag:"
+	// defaultTagInvalidationAttempts defines the number of times InvalidateTags reads the tagged keys again
+	// when the tags change before they could be removed.
+	defaultTagInvalidationAttempts = 5
+)
+
+const (
+	// levelDebug identifies the log lines only written when debugging is enabled.
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// defaultLatencyBuckets defines the upper bounds, in seconds, of the latency histograms.
+var defaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+const (
+	// EvictLRU evicts the least recently used entry.
+	EvictLRU EvictionPolicy = "lru"
+	// EvictLFU evicts the least frequently used entry, the least recently used one among equals. New entries
+	// count as used once, and the counts are halved periodically.
+	EvictLFU EvictionPolicy = "lfu"
+)
+
+const (
+	// SlowLogClient identifies the slow commands measured by the Datasource.
+	SlowLogClient SlowLogSource = "client"
+	// SlowLogServer identifies the slow commands reported by the SLOWLOG GET command of the server.
+	SlowLogServer SlowLogSource = "server"
+)
+
+const (
+	// EventPingOK is published when a keepalive ping succeeds.
+	EventPingOK EventType = iota + 1
+	// EventPingFailed is published when a keepalive ping fails, before reconnecting.
+	EventPingFailed
+	// EventReconnected is published when the connection has been re-established, including
+	// after following a sentinel failover.
+	EventReconnected
+	// EventReconnectFailed is published when a reconnection attempt fails.
+	EventReconnectFailed
+	// EventReconnectGaveUp is published when the reconnection policy gives up.
+	EventReconnectGaveUp
+	// EventCommandError is published when a command executed by the Datasource fails.
+	EventCommandError
+	// EventReplicaChanged is published on the primary Datasource of a ReplicaSet when the status
+	// of one of its replicas is updated.
+	EventReplicaChanged
+)
+
+const (
+	// StateConnecting is the state of a Datasource whose initial connection has not completed yet.
+	StateConnecting ConnState = iota
+	// StateConnected is the state of a Datasource whose last health check succeeded.
+	StateConnected
+	// StateDegraded is the state of a Datasource whose last health check failed, before any
+	// reconnection attempt, or that could not follow a sentinel failover.
+	StateDegraded
+	// StateReconnecting is the state of a Datasource whose reconnection attempts are failing.
+	StateReconnecting
+	// StateDisconnected is the state of a Datasource that could not connect, or whose reconnection
+	// policy gave up.
+	StateDisconnected
+	// StateClosed is the state of a Datasource after Close or Shutdown.
+	StateClosed
+)
+
+const (
+	// ReplicaRoundRobin distributes read-only commands evenly across the healthy replicas.
+	ReplicaRoundRobin ReplicaStrategy = iota
+	// ReplicaLowestLatency routes read-only commands to the healthy replica with the lowest ping latency.
+	ReplicaLowestLatency
+)
+
+const (
+	// GiveUpResume waits for the next keepalive ping to fail before starting a new reconnection cycle.
+	GiveUpResume GiveUpAction = "resume"
+	// GiveUpStop stops the keepalive routine; the Datasource stays disconnected until Reconnect succeeds,
+	// which starts the keepalive routine again.
+	GiveUpStop GiveUpAction = "stop"
+	// GiveUpClose closes the Datasource, as if Close had been called.
+	GiveUpClose GiveUpAction = "close"
+)
+
+const (
+	// TrackingDefault tracks the keys read through the tracked client of the Datasource.
+	TrackingDefault TrackingMode = "default"
+	// TrackingBroadcast tracks every key matching the configured prefixes.
+	TrackingBroadcast TrackingMode = "broadcast"
+)
+
+// trackingChannel is the channel the server publishes the invalidation messages of CLIENT TRACKING on.
+const trackingChannel = "__redis__:invalidate"
+
+var (
+	// errConnUnavailable is returned when an operation requires a connection that has not been established.
+	errConnUnavailable = errors.New("the redis connection is currently unavailable")
+
+	// errDatasourceClosed is returned when an operation is attempted on a closed Datasource.
+	errDatasourceClosed = errors.New("the redis datasource is closed")
+
+	// errTagsChanged is returned when the tags keep changing while InvalidateTags removes their keys.
+	errTagsChanged = errors.New("the tags kept changing while their keys were being removed")
 )
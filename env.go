@@ -0,0 +1,273 @@
+package redisc
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sivaosorg/unify4g"
+)
+
+// defaultEnvPrefix is the prefix used by LoadSettingsFromEnv when no prefix is given.
+const defaultEnvPrefix = "REDIS"
+
+// envOption binds an environment variable, named after the prefix, to a field of Settings.
+type envOption struct {
+	name  string
+	parse func(c *Settings, value string) error
+}
+
+// envOptions lists the supported environment variables, without their prefix.
+var envOptions = []envOption{
+	{"ENABLED", boolField(func(c *Settings) *bool { return &c.enabled })},
+	{"DEBUGGING", boolField(func(c *Settings) *bool { return &c.debugging })},
+	{"KEEPALIVE", boolField(func(c *Settings) *bool { return &c.keepalive })},
+	{"PING_INTERVAL", durationField(func(c *Settings) *time.Duration { return &c.pingInterval })},
+	{"SLOW_THRESHOLD", durationField(func(c *Settings) *time.Duration { return &c.slowThreshold })},
+	{"SLOW_LOG_SIZE", intField(func(c *Settings) *int { return &c.slowLogSize })},
+	{"LAZY", boolField(func(c *Settings) *bool { return &c.lazy })},
+	{"TRANSITIONS_ONLY", boolField(func(c *Settings) *bool { return &c.transitionsOnly })},
+
+	{"NETWORK", stringField(func(c *Settings) *string { return &c.conn.network })},
+	{"ADDR", stringField(func(c *Settings) *string { return &c.conn.connectionStrings })},
+	{"USERNAME", stringField(func(c *Settings) *string { return &c.conn.username })},
+	{"PASSWORD", stringField(func(c *Settings) *string { return &c.conn.password })},
+	{"DB", intField(func(c *Settings) *int { return &c.conn.database })},
+
+	{"MAX_RETRIES", intField(func(c *Settings) *int { return &c.retry.maxRetries })},
+	{"MIN_RETRY_BACKOFF", durationField(func(c *Settings) *time.Duration { return &c.retry.minRetryBackoff })},
+	{"MAX_RETRY_BACKOFF", durationField(func(c *Settings) *time.Duration { return &c.retry.maxRetryBackoff })},
+
+	{"DIAL_TIMEOUT", durationField(func(c *Settings) *time.Duration { return &c.timeout.connTimeout })},
+	{"READ_TIMEOUT", durationField(func(c *Settings) *time.Duration { return &c.timeout.readTimeout })},
+	{"WRITE_TIMEOUT", durationField(func(c *Settings) *time.Duration { return &c.timeout.writeTimeout })},
+
+	{"POOL_SIZE", intField(func(c *Settings) *int { return &c.pool.poolSize })},
+	{"MIN_IDLE_CONNS", intField(func(c *Settings) *int { return &c.pool.minIdleConn })},
+	{"MAX_CONN_AGE", durationField(func(c *Settings) *time.Duration { return &c.pool.maxConnAge })},
+	{"POOL_TIMEOUT", durationField(func(c *Settings) *time.Duration { return &c.pool.poolTimeout })},
+	{"IDLE_TIMEOUT", durationField(func(c *Settings) *time.Duration { return &c.pool.idleTimeout })},
+	{"IDLE_CHECK_FREQUENCY", durationField(func(c *Settings) *time.Duration { return &c.pool.idleCheckFrequency })},
+
+	{"SENTINEL_ENABLED", boolField(func(c *Settings) *bool { return &c.sentinel.enabled })},
+	{"SENTINEL_MASTER_NAME", stringField(func(c *Settings) *string { return &c.sentinel.masterName })},
+	{"SENTINEL_ADDRS", stringsField(func(c *Settings) *[]string { return &c.sentinel.addrs })},
+	{"SENTINEL_PASSWORD", stringField(func(c *Settings) *string { return &c.sentinel.password })},
+
+	{"CLUSTER_ENABLED", boolField(func(c *Settings) *bool { return &c.cluster.enabled })},
+	{"CLUSTER_ADDRS", stringsField(func(c *Settings) *[]string { return &c.cluster.addrs })},
+	{"CLUSTER_READ_ONLY", boolField(func(c *Settings) *bool { return &c.cluster.readOnly })},
+	{"CLUSTER_ROUTE_BY_LATENCY", boolField(func(c *Settings) *bool { return &c.cluster.routeByLatency })},
+	{"CLUSTER_ROUTE_RANDOMLY", boolField(func(c *Settings) *bool { return &c.cluster.routeRandomly })},
+	{"CLUSTER_MAX_REDIRECTS", intField(func(c *Settings) *int { return &c.cluster.maxRedirects })},
+
+	{"TLS_ENABLED", boolField(func(c *Settings) *bool { return &c.tls.enabled })},
+	{"TLS_CA_FILE", stringField(func(c *Settings) *string { return &c.tls.caFile })},
+	{"TLS_CERT_FILE", stringField(func(c *Settings) *string { return &c.tls.certFile })},
+	{"TLS_KEY_FILE", stringField(func(c *Settings) *string { return &c.tls.keyFile })},
+	{"TLS_SERVER_NAME", stringField(func(c *Settings) *string { return &c.tls.serverName })},
+	{"TLS_INSECURE_SKIP_VERIFY", boolField(func(c *Settings) *bool { return &c.tls.insecureSkipVerify })},
+	{"TLS_MIN_VERSION", tlsVersionField(func(c *Settings) *uint16 { return &c.tls.minVersion })},
+
+	{"RECONNECT_INITIAL_DELAY", durationField(func(c *Settings) *time.Duration { return &c.reconnect.initialDelay })},
+	{"RECONNECT_MULTIPLIER", floatField(func(c *Settings) *float64 { return &c.reconnect.multiplier })},
+	{"RECONNECT_MAX_DELAY", durationField(func(c *Settings) *time.Duration { return &c.reconnect.maxDelay })},
+	{"RECONNECT_JITTER", floatField(func(c *Settings) *float64 { return &c.reconnect.jitter })},
+	{"RECONNECT_MAX_ATTEMPTS", intField(func(c *Settings) *int { return &c.reconnect.maxAttempts })},
+	{"RECONNECT_GIVE_UP", choiceField(func(c *Settings) *string { return (*string)(&c.reconnect.giveUp) },
+		string(GiveUpResume), string(GiveUpStop), string(GiveUpClose))},
+
+	{"TRACKING_ENABLED", boolField(func(c *Settings) *bool { return &c.tracking.enabled })},
+	{"TRACKING_MODE", choiceField(func(c *Settings) *string { return (*string)(&c.tracking.mode) },
+		string(TrackingDefault), string(TrackingBroadcast))},
+	{"TRACKING_PREFIXES", stringsField(func(c *Settings) *[]string { return &c.tracking.prefixes })},
+}
+
+// envServers lists the environment variables, without their prefix, that name a server to connect to.
+var envServers = []string{"URL", "ADDR", "SENTINEL_ADDRS", "CLUSTER_ADDRS"}
+
+// LoadSettingsFromEnv creates Settings from environment variables named after the given prefix,
+// e.g. REDIS_ADDR, REDIS_POOL_SIZE or REDIS_PING_INTERVAL=30s for the prefix "REDIS" (the default
+// when prefix is empty). If <PREFIX>_URL is set, it is parsed with ParseSettings first and the other
+// variables override the values it defines. Variables that are not set keep the defaults of NewSettings.
+// The Settings are enabled if a server is named by <PREFIX>_URL, <PREFIX>_ADDR, <PREFIX>_SENTINEL_ADDRS
+// or <PREFIX>_CLUSTER_ADDRS, unless <PREFIX>_ENABLED says otherwise.
+//
+// Durations accept Go duration strings (e.g. "3s") or a plain number of seconds, booleans accept the
+// values understood by strconv.ParseBool, lists (<PREFIX>_SENTINEL_ADDRS, <PREFIX>_CLUSTER_ADDRS,
+// <PREFIX>_TRACKING_PREFIXES) are comma-separated, <PREFIX>_TLS_MIN_VERSION accepts "1.0" to "1.3",
+// <PREFIX>_RECONNECT_GIVE_UP accepts "resume", "stop" or "close" and <PREFIX>_TRACKING_MODE accepts
+// "default" or "broadcast".
+//
+// Returns:
+//   - the loaded Settings;
+//   - an error naming every variable that failed to parse, <PREFIX>_URL included.
+func LoadSettingsFromEnv(prefix string) (*Settings, error) {
+	prefix = strings.TrimSuffix(prefix, "_")
+	if unify4g.IsEmpty(prefix) {
+		prefix = defaultEnvPrefix
+	}
+	s := NewSettings()
+	var errs []error
+	if value, ok := os.LookupEnv(prefix + "_URL"); ok {
+		parsed, err := ParseSettings(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("the environment variable '%s_URL' is invalid: %v", prefix, err))
+		} else {
+			s = parsed
+		}
+	}
+	enabled := false
+	for _, name := range envServers {
+		if _, ok := os.LookupEnv(prefix + "_" + name); ok {
+			enabled = true
+		}
+	}
+	s.SetEnable(enabled)
+	for _, option := range envOptions {
+		name := prefix + "_" + option.name
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := option.parse(s, strings.TrimSpace(value)); err != nil {
+			errs = append(errs, fmt.Errorf("the environment variable '%s' %v", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return s, nil
+}
+
+// stringField returns a parse function that assigns the raw value to the Settings field returned by field.
+func stringField(field func(c *Settings) *string) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// stringsField returns a parse function that assigns the comma-separated, non-empty items of the value
+// to the Settings field returned by field.
+func stringsField(field func(c *Settings) *[]string) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); unify4g.IsNotEmpty(item) {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
+
+// choiceField returns a parse function that assigns the value, which must be one of choices, to the
+// Settings field returned by field.
+func choiceField(field func(c *Settings) *string, choices ...string) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		for _, choice := range choices {
+			if value == choice {
+				*field(c) = value
+				return nil
+			}
+		}
+		return fmt.Errorf("is not one of %s: '%s'", strings.Join(choices, ", "), value)
+	}
+}
+
+// boolField returns a parse function that assigns a boolean value to the Settings field returned by field.
+func boolField(field func(c *Settings) *bool) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("is not a boolean: '%s'", value)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// intField returns a parse function that assigns an integer value to the Settings field returned by field.
+func intField(field func(c *Settings) *int) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("is not a number: '%s'", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// floatField returns a parse function that assigns a decimal value to the Settings field returned by field.
+func floatField(field func(c *Settings) *float64) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("is not a number: '%s'", value)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+// durationField returns a parse function that assigns a duration value to the Settings field returned by field.
+func durationField(field func(c *Settings) *time.Duration) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		d, err := parseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// tlsVersionField returns a parse function that assigns a TLS version ("1.0" to "1.3")
+// to the Settings field returned by field.
+func tlsVersionField(field func(c *Settings) *uint16) func(c *Settings, value string) error {
+	return func(c *Settings, value string) error {
+		version, err := parseTLSVersion(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = version
+		return nil
+	}
+}
+
+// parseTLSVersion converts a TLS version such as "1.2" (or "TLS1.2") into its crypto/tls constant.
+func parseTLSVersion(value string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(value), "TLS") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("is not a supported TLS version: '%s'", value)
+}
+
+// formatTLSVersion converts a crypto/tls version constant into its "1.x" form, as accepted by parseTLSVersion.
+func formatTLSVersion(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	}
+	return strconv.Itoa(int(version))
+}
@@ -0,0 +1,142 @@
+package redisc
+
+import (
+	"crypto/tls"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadSettingsFromEnv(t *testing.T) {
+	t.Setenv("CACHE_URL", "redis://:secret@cache.example.com:6380/1?pool_size=20")
+	t.Setenv("CACHE_DB", "3")
+	t.Setenv("CACHE_PING_INTERVAL", "45")
+	t.Setenv("CACHE_KEEPALIVE", "true")
+	t.Setenv("CACHE_CLUSTER_ADDRS", " a:7000, ,b:7001 ")
+	t.Setenv("CACHE_TLS_MIN_VERSION", "TLS1.3")
+	t.Setenv("CACHE_CLUSTER_MAX_REDIRECTS", "5")
+
+	s, err := LoadSettingsFromEnv("CACHE_")
+	if err != nil {
+		t.Fatalf("LoadSettingsFromEnv() error = %v", err)
+	}
+	if s.conn.connectionStrings != "cache.example.com:6380" || s.conn.password != "secret" || s.pool.poolSize != 20 {
+		t.Errorf("CACHE_URL not applied: addr %q, pool size %d", s.conn.connectionStrings, s.pool.poolSize)
+	}
+	if s.conn.database != 3 {
+		t.Errorf("database = %d, want CACHE_DB to override CACHE_URL", s.conn.database)
+	}
+	if s.pingInterval != 45*time.Second || !s.keepalive {
+		t.Errorf("ping interval = %v, keepalive = %v, want 45s and true", s.pingInterval, s.keepalive)
+	}
+	if want := []string{"a:7000", "b:7001"}; !reflect.DeepEqual(s.cluster.addrs, want) {
+		t.Errorf("cluster addrs = %q, want %q", s.cluster.addrs, want)
+	}
+	if s.tls.minVersion != tls.VersionTLS13 || s.cluster.maxRedirects != 5 {
+		t.Errorf("tls min version = %x, max redirects = %d", s.tls.minVersion, s.cluster.maxRedirects)
+	}
+}
+
+func TestLoadSettingsFromEnvDefaultPrefix(t *testing.T) {
+	t.Setenv("REDIS_ADDR", "10.0.0.1:6379")
+	s, err := LoadSettingsFromEnv("")
+	if err != nil {
+		t.Fatalf("LoadSettingsFromEnv() error = %v", err)
+	}
+	if s.conn.connectionStrings != "10.0.0.1:6379" {
+		t.Errorf("addr = %q, want REDIS_ADDR", s.conn.connectionStrings)
+	}
+	if defaults := NewSettings(); s.pool.poolSize != defaults.pool.poolSize {
+		t.Errorf("pool size = %d, want the default %d", s.pool.poolSize, defaults.pool.poolSize)
+	}
+}
+
+func TestLoadSettingsFromEnvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{"invalid url", map[string]string{
+			"BAD_URL":       "http://localhost",
+			"BAD_POOL_SIZE": "many",
+		}, []string{
+			"the environment variable 'BAD_URL' is invalid",
+			"the environment variable 'BAD_POOL_SIZE' is not a number: 'many'",
+		}},
+		{"every invalid variable", map[string]string{
+			"BAD_POOL_SIZE":             "many",
+			"BAD_KEEPALIVE":             "sometimes",
+			"BAD_READ_TIMEOUT":          "soon",
+			"BAD_MIN_IDLE_CONNS":        "few",
+			"BAD_TLS_MIN_VERSION":       "1.4",
+			"BAD_ADDR":                  "localhost:6379",
+			"BAD_CLUSTER_MAX_REDIRECTS": "3",
+			"BAD_RECONNECT_GIVE_UP":     "retry",
+			"BAD_TRACKING_MODE":         "optin",
+		}, []string{
+			"the environment variable 'BAD_KEEPALIVE' is not a boolean: 'sometimes'",
+			"the environment variable 'BAD_READ_TIMEOUT' is not a valid duration: 'soon'",
+			"the environment variable 'BAD_POOL_SIZE' is not a number: 'many'",
+			"the environment variable 'BAD_TLS_MIN_VERSION' is not a supported TLS version: '1.4'",
+			"the environment variable 'BAD_MIN_IDLE_CONNS' is not a number: 'few'",
+			"the environment variable 'BAD_RECONNECT_GIVE_UP' is not one of resume, stop, close: 'retry'",
+			"the environment variable 'BAD_TRACKING_MODE' is not one of default, broadcast: 'optin'",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			s, err := LoadSettingsFromEnv("BAD")
+			if err == nil {
+				t.Fatalf("LoadSettingsFromEnv() = %v, want an error", s)
+			}
+			if len(tt.want) > 1 {
+				joined, ok := err.(interface{ Unwrap() []error })
+				if !ok {
+					t.Fatalf("LoadSettingsFromEnv() error %T does not join the problems", err)
+				}
+				if got := len(joined.Unwrap()); got != len(tt.want) {
+					t.Errorf("LoadSettingsFromEnv() reported %d problems, want %d:\n%v", got, len(tt.want), err)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadSettingsFromEnv() error is missing %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSettingsFromEnvEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"no server", map[string]string{"ENV_POOL_SIZE": "5"}, false},
+		{"url", map[string]string{"ENV_URL": "redis://localhost:6379"}, true},
+		{"addr", map[string]string{"ENV_ADDR": "localhost:6379"}, true},
+		{"cluster addrs", map[string]string{"ENV_CLUSTER_ADDRS": "a:7000,b:7001"}, true},
+		{"url disabled", map[string]string{"ENV_URL": "redis://localhost:6379", "ENV_ENABLED": "false"}, false},
+		{"enabled without server", map[string]string{"ENV_ENABLED": "true"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			s, err := LoadSettingsFromEnv("ENV")
+			if err != nil {
+				t.Fatalf("LoadSettingsFromEnv() error = %v", err)
+			}
+			if s.IsEnabled() != tt.want {
+				t.Errorf("IsEnabled() = %v, want %v", s.IsEnabled(), tt.want)
+			}
+		})
+	}
+}
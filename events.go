@@ -0,0 +1,168 @@
+package redisc
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// String returns the name of the event type, e.g. "ping_ok".
+func (t EventType) String() string {
+	switch t {
+	case EventPingOK:
+		return "ping_ok"
+	case EventPingFailed:
+		return "ping_failed"
+	case EventReconnected:
+		return "reconnected"
+	case EventReconnectFailed:
+		return "reconnect_failed"
+	case EventReconnectGaveUp:
+		return "reconnect_gave_up"
+	case EventCommandError:
+		return "command_error"
+	case EventReplicaChanged:
+		return "replica_changed"
+	}
+	return "event(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Subscribe registers a handler for the given event types, or for every event type if none is given,
+// with a buffer of defaultEventBufferSize events. See SubscribeBuffer.
+func (d *Datasource) Subscribe(handler func(event Event), types ...EventType) *Subscription {
+	return d.SubscribeBuffer(defaultEventBufferSize, handler, types...)
+}
+
+// SubscribeBuffer registers a handler for the given event types, or for every event type if none is given.
+// The events are queued in a buffer of the given size (defaultEventBufferSize if size is not positive) and
+// delivered in order by a dedicated goroutine, so a slow handler never blocks the Datasource nor the other
+// subscribers; the events that do not fit in the buffer are dropped and counted by Subscription.Dropped.
+// Any number of handlers may be registered. Once the Datasource is closed, the returned Subscription
+// receives no event.
+//
+// Returns:
+//   - the Subscription, whose Unsubscribe method stops the delivery of events to the handler.
+func (d *Datasource) SubscribeBuffer(size int, handler func(event Event), types ...EventType) *Subscription {
+	if size <= 0 {
+		size = defaultEventBufferSize
+	}
+	s := &Subscription{queue: make(chan Event, size)}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	if !d.events.add(s, &d.callbacks) {
+		return s
+	}
+	go func() {
+		defer d.callbacks.Done()
+		for event := range s.queue {
+			handler(event)
+		}
+	}()
+	return s
+}
+
+// Unsubscribe stops the delivery of events to the handler of the Subscription. The events already
+// queued are still delivered. Calling Unsubscribe more than once, or on a nil Subscription, has no effect.
+func (s *Subscription) Unsubscribe() {
+	if s == nil || s.bus == nil {
+		return
+	}
+	s.bus.remove(s)
+}
+
+// Dropped returns the number of events dropped because the buffer of the Subscription was full.
+func (s *Subscription) Dropped() uint64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// DroppedEvents returns the number of events dropped across every Subscription of the Datasource
+// because their buffer was full.
+func (d *Datasource) DroppedEvents() uint64 {
+	return atomic.LoadUint64(&d.events.dropped)
+}
+
+// accepts returns true if the Subscription is registered for the given event type.
+func (s *Subscription) accepts(t EventType) bool {
+	return len(s.types) == 0 || s.types[t]
+}
+
+// notify publishes an event of the given type that does not change the connection state,
+// such as a command error, to the subscribers of the Datasource. Command errors are returned
+// to the caller, so they are only logged when debugging is enabled in the Settings.
+func (d *Datasource) notify(t EventType, response wrapify.R) {
+	if t != EventCommandError || d.conf.IsDebugging() {
+		d.logResponse(levelOf(t), response)
+	}
+	d.events.publish(Event{Type: t, State: d.State(), Response: response, At: time.Now()})
+}
+
+// add registers the Subscription on the bus and accounts for its delivery routine in wg.
+//
+// Returns:
+//   - true if the Subscription has been registered;
+//   - false if the bus is closed.
+func (b *eventBus) add(s *Subscription, wg *sync.WaitGroup) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	if b.subscriptions == nil {
+		b.subscriptions = make(map[uint64]*Subscription)
+	}
+	b.next++
+	s.bus, s.id = b, b.next
+	b.subscriptions[s.id] = s
+	wg.Add(1)
+	return true
+}
+
+// remove unregisters the Subscription and closes its buffer, so that its delivery routine
+// returns once the queued events have been delivered.
+func (b *eventBus) remove(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscriptions[s.id]; ok {
+		delete(b.subscriptions, s.id)
+		close(s.queue)
+	}
+}
+
+// publish queues the event on every Subscription registered for its type, without blocking:
+// the event is dropped for the subscriptions whose buffer is full.
+func (b *eventBus) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subscriptions {
+		if !s.accepts(event.Type) {
+			continue
+		}
+		select {
+		case s.queue <- event:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+			atomic.AddUint64(&b.dropped, 1)
+		}
+	}
+}
+
+// close unregisters every Subscription and rejects the subsequent ones. Calling close more than once has no effect.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for id, s := range b.subscriptions {
+		delete(b.subscriptions, id)
+		close(s.queue)
+	}
+}
@@ -0,0 +1,158 @@
+package redisc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// recordingLogger records the messages logged at the error level.
+type recordingLogger struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *recordingLogger) Debug(msg string, fields ...interface{}) {}
+func (l *recordingLogger) Info(msg string, fields ...interface{})  {}
+func (l *recordingLogger) Warn(msg string, fields ...interface{})  {}
+
+func (l *recordingLogger) Error(msg string, fields ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+func TestSubscriptionDropsWhenFull(t *testing.T) {
+	d := NewClient(*NewSettings())
+	started, release := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	var delivered []EventType
+	s := d.SubscribeBuffer(2, func(event Event) {
+		mu.Lock()
+		delivered = append(delivered, event.Type)
+		first := len(delivered) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+	})
+	other := d.Subscribe(func(event Event) {})
+
+	// The first event blocks the handler, the next two fill the buffer and the others are dropped.
+	d.events.publish(Event{Type: EventPingOK})
+	<-started
+	for _, t := range []EventType{EventPingFailed, EventReconnected, EventReconnectFailed, EventCommandError} {
+		d.events.publish(Event{Type: t})
+	}
+	if got := s.Dropped(); got != 2 {
+		t.Errorf("Dropped() = %d, want 2", got)
+	}
+	if got := other.Dropped(); got != 0 {
+		t.Errorf("Dropped() = %d for a subscription with room, want 0", got)
+	}
+	if got := d.DroppedEvents(); got != 2 {
+		t.Errorf("DroppedEvents() = %d, want 2", got)
+	}
+
+	close(release)
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventType{EventPingOK, EventPingFailed, EventReconnected}
+	if len(delivered) != len(want) {
+		t.Fatalf("delivered %v, want %v", delivered, want)
+	}
+	for i := range want {
+		if delivered[i] != want[i] {
+			t.Errorf("delivered %v, want %v in order", delivered, want)
+			break
+		}
+	}
+}
+
+func TestSubscriptionFilterAndUnsubscribe(t *testing.T) {
+	d := NewClient(*NewSettings())
+	events := make(chan EventType, 8)
+	s := d.Subscribe(func(event Event) { events <- event.Type }, EventPingFailed, EventReconnected)
+
+	d.events.publish(Event{Type: EventPingOK})
+	d.events.publish(Event{Type: EventPingFailed})
+	d.events.publish(Event{Type: EventReconnected})
+	for _, want := range []EventType{EventPingFailed, EventReconnected} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("delivered %v, want %v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v not delivered", want)
+		}
+	}
+
+	s.Unsubscribe()
+	s.Unsubscribe()
+	var none *Subscription
+	none.Unsubscribe()
+	d.events.publish(Event{Type: EventPingFailed})
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case got := <-events:
+		t.Errorf("%v delivered after Unsubscribe", got)
+	default:
+	}
+	if got := s.Dropped(); got != 0 {
+		t.Errorf("Dropped() = %d after Unsubscribe, want 0", got)
+	}
+}
+
+func TestSubscribeAfterClose(t *testing.T) {
+	d := NewClient(*NewSettings())
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	called := make(chan struct{}, 1)
+	s := d.Subscribe(func(event Event) { called <- struct{}{} })
+	d.events.publish(Event{Type: EventPingOK})
+	s.Unsubscribe()
+	select {
+	case <-called:
+		t.Error("event delivered to a subscription made after Close")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestEventTypeString(t *testing.T) {
+	tests := []struct {
+		t    EventType
+		want string
+	}{
+		{EventPingOK, "ping_ok"},
+		{EventReconnectGaveUp, "reconnect_gave_up"},
+		{EventReplicaChanged, "replica_changed"},
+		{EventType(99), "event(99)"},
+	}
+	for _, tt := range tests {
+		if got := tt.t.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNotifyLogsCommandErrorsWhenDebugging(t *testing.T) {
+	for _, debugging := range []bool{false, true} {
+		logger := &recordingLogger{}
+		d := NewClient(*NewSettings().SetDebug(debugging)).SetLogger(logger)
+		d.notify(EventCommandError, wrapify.WrapInternalServerError("Failed to retrieve the key 'a'", nil).Reply())
+		d.Close()
+		if got := len(logger.errors); (got > 0) != debugging {
+			t.Errorf("debugging %v: %d command errors logged", debugging, got)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package redisc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Health returns the health of the Datasource, built from its current connection status (see Wrap),
+// the latency of the last ping, the number of failed reconnection attempts and the pool statistics.
+// In cluster mode, the pool is never reported as exhausted, as its statistics are summed over every node.
+func (d *Datasource) Health() Health {
+	response := d.Wrap()
+	h := Health{
+		State:             d.State(),
+		Connected:         d.IsConnected(),
+		StatusCode:        response.StatusCode(),
+		Message:           response.Message(),
+		Debugging:         response.Debugging(),
+		Master:            d.Master(),
+		Latency:           d.Latency().String(),
+		ReconnectAttempts: d.ReconnectAttempts(),
+		Pool:              d.poolHealth(),
+	}
+	if err := response.Cause(); err != nil {
+		h.Error = err.Error()
+	}
+	h.Ready = h.Connected && (h.Pool == nil || !h.Pool.Exhausted)
+	return h
+}
+
+// LivenessHandler returns an http.Handler for liveness probes. It answers 200 OK as long as the
+// Datasource is not closed, even while the redis server is unreachable, since the Datasource keeps
+// reconnecting on its own and restarting the process would not help; it answers 503 once closed.
+func (d *Datasource) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.IsClosed() {
+			writeProbe(w, http.StatusServiceUnavailable, StateClosed.String(), "the redis datasource is closed")
+			return
+		}
+		writeProbe(w, http.StatusOK, "ok", "")
+	})
+}
+
+// ReadinessHandler returns an http.Handler for readiness probes. It answers 200 OK when the Datasource
+// is connected and its connection pool is not exhausted, or 503 otherwise.
+func (d *Datasource) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := d.Health()
+		switch {
+		case !h.Connected:
+			writeProbe(w, http.StatusServiceUnavailable, h.State.String(), h.Message)
+		case !h.Ready:
+			writeProbe(w, http.StatusServiceUnavailable, "exhausted", "the redis connection pool is exhausted")
+		default:
+			writeProbe(w, http.StatusOK, "ok", "")
+		}
+	})
+}
+
+// HealthHandler returns an http.Handler serving the Health of the Datasource as JSON,
+// with 200 OK when the Datasource is ready, or 503 otherwise.
+func (d *Datasource) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := d.Health()
+		status := http.StatusOK
+		if !h.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, h)
+	})
+}
+
+// poolHealth returns the statistics of the connection pool, or nil if there is no connection.
+// In cluster mode, the statistics are summed over every node while the size is that of the pool of a
+// single node, so whether a node has exhausted its pool cannot be told: the pool is never reported as
+// exhausted, and a connected cluster Datasource is always ready.
+func (d *Datasource) poolHealth() *PoolHealth {
+	var h PoolHealth
+	if conn := d.Conn(); conn != nil {
+		stats := conn.PoolStats()
+		h = PoolHealth{Size: conn.Options().PoolSize, TotalConns: stats.TotalConns, IdleConns: stats.IdleConns,
+			StaleConns: stats.StaleConns, Hits: stats.Hits, Misses: stats.Misses, Timeouts: stats.Timeouts}
+		h.Exhausted = h.Size > 0 && int(h.TotalConns) >= h.Size && h.IdleConns == 0
+	} else if cluster := d.ClusterConn(); cluster != nil {
+		stats := cluster.PoolStats()
+		h = PoolHealth{Size: cluster.Options().PoolSize, TotalConns: stats.TotalConns, IdleConns: stats.IdleConns,
+			StaleConns: stats.StaleConns, Hits: stats.Hits, Misses: stats.Misses, Timeouts: stats.Timeouts}
+	} else {
+		return nil
+	}
+	return &h
+}
+
+// writeProbe writes the JSON body of a probe, e.g. {"status":"ok"}, with the given status code.
+func writeProbe(w http.ResponseWriter, code int, status, reason string) {
+	body := map[string]string{"status": status}
+	if reason != "" {
+		body["reason"] = reason
+	}
+	writeJSON(w, code, body)
+}
+
+// writeJSON writes v as the JSON body of the response, with the given status code. If v cannot be
+// encoded, e.g. because of a debugging value of the connection status, a 500 error is written instead.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, fmt.Sprintf("the health of the redis datasource could not be encoded: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(append(data, '\n'))
+}
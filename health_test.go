@@ -0,0 +1,99 @@
+package redisc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serve returns the response of h to a GET request.
+func serve(h http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestHealth(t *testing.T) {
+	d := NewClient(*NewSettings())
+	h := d.Health()
+	if h.Connected || h.Ready || h.Pool != nil {
+		t.Errorf("Health() = connected %v, ready %v, pool %v for a never connected datasource", h.Connected, h.Ready, h.Pool)
+	}
+	d.Close()
+	if h := d.Health(); h.State != StateClosed || h.Ready {
+		t.Errorf("Health() = state %v, ready %v after Close", h.State, h.Ready)
+	}
+
+	h = newFakeDatasource(t, newFakeServer(t)).Health()
+	if !h.Connected || !h.Ready || h.Pool == nil || h.Pool.Exhausted {
+		t.Errorf("Health() = connected %v, ready %v, pool %+v for a connected datasource", h.Connected, h.Ready, h.Pool)
+	}
+}
+
+func TestProbeHandlers(t *testing.T) {
+	never := NewClient(*NewSettings())
+	defer never.Close()
+	closed := NewClient(*NewSettings())
+	closed.Close()
+	connected := newFakeDatasource(t, newFakeServer(t))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		code    int
+		status  string
+	}{
+		{"liveness of a never connected datasource", never.LivenessHandler(), http.StatusOK, "ok"},
+		{"liveness of a closed datasource", closed.LivenessHandler(), http.StatusServiceUnavailable, "closed"},
+		{"readiness of a never connected datasource", never.ReadinessHandler(), http.StatusServiceUnavailable, never.State().String()},
+		{"readiness of a closed datasource", closed.ReadinessHandler(), http.StatusServiceUnavailable, "closed"},
+		{"readiness of a connected datasource", connected.ReadinessHandler(), http.StatusOK, "ok"},
+	}
+	for _, tt := range tests {
+		w := serve(tt.handler)
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type = %q", tt.name, got)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["status"] != tt.status {
+			t.Errorf("%s: body = %s, want the status %q", tt.name, w.Body, tt.status)
+		}
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		d    *Datasource
+		code int
+	}{
+		{"never connected", NewClient(*NewSettings()), http.StatusServiceUnavailable},
+		{"connected", newFakeDatasource(t, newFakeServer(t)), http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serve(tt.d.HealthHandler())
+		if w.Code != tt.code {
+			t.Errorf("%s: code = %d, want %d", tt.name, w.Code, tt.code)
+		}
+		var h map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &h); err != nil {
+			t.Fatalf("%s: body %s is not JSON: %v", tt.name, w.Body, err)
+		}
+		if h["ready"] != (tt.code == http.StatusOK) || h["connected"] != (tt.code == http.StatusOK) {
+			t.Errorf("%s: ready %v, connected %v", tt.name, h["ready"], h["connected"])
+		}
+		tt.d.Close()
+	}
+}
+
+func TestWriteJSONEncodingError(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"debugging": func() {}})
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d, want %d for a value that cannot be encoded", w.Code, http.StatusInternalServerError)
+	}
+}
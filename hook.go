@@ -0,0 +1,236 @@
+package redisc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// routeKey is the context key under which the Datasource hands the route of a command to the hooks.
+type routeKey struct{}
+
+// AddHook registers a hook invoked around every command and pipeline processed by the Datasource,
+// including those sent through the clients returned by Conn, ClusterConn and Client. Hooks apply
+// immediately to the current connection and to every connection created later on reconnection.
+//
+// The Before methods of the hooks are called in registration order and the After methods in reverse
+// order, so that the first hook registered wraps all the others. When a Before method fails, the command
+// is not sent, only the hooks whose Before method succeeded have their After method called, and the error
+// is returned by Process. Note that the typed commands of go-redis v6 (e.g. Get) do not expose the error
+// returned by Process, only the one recorded in the command.
+//
+// It returns the updated Datasource for method chaining.
+func (d *Datasource) AddHook(hook Hook) *Datasource {
+	if hook == nil {
+		return d
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hooks := make([]Hook, len(d.hooks), len(d.hooks)+1)
+	copy(hooks, d.hooks)
+	d.hooks = append(hooks, hook)
+	return d
+}
+
+// Hooks returns the hooks registered on the Datasource, in registration order.
+func (d *Datasource) Hooks() []Hook {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.hooks
+}
+
+// instrument installs on the client the single interception point of the Datasource, which records
+// the command metrics and the slow commands, including those sent in pipelines, and runs the registered
+// hooks. As the hooks are looked up on every call, it is applied once to every connection the
+// Datasource creates.
+//
+// The hooks receive the context of the operation when the command is sent through a client returned by
+// bind, and a background context otherwise. Their context also carries the route of the command, read
+// by routeOf.
+func (d *Datasource) instrument(client redis.UniversalClient) {
+	server := route{network: "tcp"}
+	_, cluster := client.(*redis.ClusterClient)
+	if c, ok := client.(*redis.Client); ok {
+		opt := c.Options()
+		server = route{network: opt.Network, addr: opt.Addr, database: opt.DB}
+	}
+	client.WrapProcess(func(process func(cmd redis.Cmder) error) func(cmd redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			c := d.callOf(cmd)
+			hooks := d.Hooks()
+			ctx := c.context()
+			if len(hooks) > 0 {
+				r := server
+				ctx = context.WithValue(ctx, routeKey{}, &r)
+				// The node serving the command is only known once the cluster client has routed it.
+				if cluster {
+					d.routes.Store(cmd, &r)
+					defer d.routes.Delete(cmd)
+				}
+			}
+			return runHooks(ctx, hooks,
+				func(h Hook, ctx context.Context) (context.Context, error) { return h.BeforeProcess(ctx, cmd) },
+				func(h Hook, ctx context.Context) error { return h.AfterProcess(ctx, cmd) },
+				func() error {
+					start := time.Now()
+					err := process(cmd)
+					duration := time.Since(start)
+					d.metrics.observeCommand(cmd.Name(), duration, err)
+					d.observeSlow(cmd, start, duration, c)
+					return err
+				})
+		}
+	})
+	client.WrapProcessPipeline(func(process func(cmds []redis.Cmder) error) func(cmds []redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			var c *call
+			if len(cmds) > 0 {
+				c = d.callOf(cmds[0])
+			}
+			hooks := d.Hooks()
+			ctx := c.context()
+			if len(hooks) > 0 {
+				// The commands of a cluster pipeline may be served by several nodes, none of which is reported.
+				r := server
+				ctx = context.WithValue(ctx, routeKey{}, &r)
+			}
+			return runHooks(ctx, hooks,
+				func(h Hook, ctx context.Context) (context.Context, error) { return h.BeforeProcessPipeline(ctx, cmds) },
+				func(h Hook, ctx context.Context) error { return h.AfterProcessPipeline(ctx, cmds) },
+				func() error {
+					start := time.Now()
+					err := process(cmds)
+					duration := time.Since(start)
+					// The commands of a pipeline share a single round trip, whose duration is recorded for each of them.
+					for _, cmd := range cmds {
+						d.metrics.observeCommand(cmd.Name(), duration, cmd.Err())
+						d.observeSlow(cmd, start, duration, c)
+					}
+					return err
+				})
+		}
+	})
+}
+
+// instrumentNode records the address of the cluster node as the route of every command it serves on
+// behalf of the cluster connection. It is set as the OnNewNode callback of the cluster options.
+func (d *Datasource) instrumentNode(node *redis.Client) {
+	addr := node.Options().Addr
+	node.WrapProcess(func(process func(cmd redis.Cmder) error) func(cmd redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			if r, ok := d.routes.Load(cmd); ok {
+				r.(*route).addr = addr
+			}
+			return process(cmd)
+		}
+	})
+}
+
+// bind returns a copy of the client, instrumented by the Datasource, whose commands are processed on
+// behalf of the operation of the caller: the hooks receive ctx, and the slow log records the location
+// of the caller of bind. Both are captured before the commands are sent from another goroutine by
+// await, which would lose them otherwise.
+//
+// As go-redis v6 does not hand the context of a client to its process functions, the bound copy records
+// the call of every command it sends in the calls map of the Datasource, keyed by command, where the
+// instrumentation looks it up. Binding costs a WithContext clone of the client and two wrap closures,
+// and every command sent through the copy adds a store and a delete on the map; the commands sent
+// through Conn, ClusterConn or Client only pay a lookup.
+func (d *Datasource) bind(ctx context.Context, client redis.UniversalClient) redis.UniversalClient {
+	c := &call{ctx: ctx, callers: callers()}
+	var bound redis.UniversalClient
+	switch client := client.(type) {
+	case *redis.Client:
+		bound = client.WithContext(ctx)
+	case *redis.ClusterClient:
+		bound = client.WithContext(ctx)
+	default:
+		return client
+	}
+	bound.WrapProcess(func(process func(cmd redis.Cmder) error) func(cmd redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			d.calls.Store(cmd, c)
+			defer d.calls.Delete(cmd)
+			return process(cmd)
+		}
+	})
+	bound.WrapProcessPipeline(func(process func(cmds []redis.Cmder) error) func(cmds []redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			if len(cmds) > 0 {
+				d.calls.Store(cmds[0], c)
+				defer d.calls.Delete(cmds[0])
+			}
+			return process(cmds)
+		}
+	})
+	return bound
+}
+
+// callOf returns the call the command is sent for, or nil if it was not sent through a bound client.
+func (d *Datasource) callOf(cmd redis.Cmder) *call {
+	if c, ok := d.calls.Load(cmd); ok {
+		return c.(*call)
+	}
+	return nil
+}
+
+// context returns the context of the call, or a background context if c is nil.
+func (c *call) context() context.Context {
+	if c == nil || c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// caller returns the location of the caller of the call, or that of the current goroutine if c is nil.
+func (c *call) caller() string {
+	if c == nil {
+		return caller(callers())
+	}
+	return caller(c.callers)
+}
+
+// routeOf returns the route of the command carried by the context of a hook, if any.
+func routeOf(ctx context.Context) (*route, bool) {
+	r, ok := ctx.Value(routeKey{}).(*route)
+	return r, ok
+}
+
+// runHooks calls the before function of every hook in order, starting from ctx, then process, then the
+// after function of the hooks whose before function succeeded, in reverse order.
+//
+// Returns:
+//   - the error of the first failing before function, or else the error of process;
+//   - otherwise, the first error returned by an after function.
+func runHooks(ctx context.Context, hooks []Hook,
+	before func(h Hook, ctx context.Context) (context.Context, error),
+	after func(h Hook, ctx context.Context) error,
+	process func() error,
+) error {
+	if len(hooks) == 0 {
+		return process()
+	}
+	contexts := make([]context.Context, 0, len(hooks))
+	var err error
+	for _, hook := range hooks {
+		next, e := before(hook, ctx)
+		if e != nil {
+			err = e
+			break
+		}
+		if next != nil {
+			ctx = next
+		}
+		contexts = append(contexts, ctx)
+	}
+	if err == nil {
+		err = process()
+	}
+	for i := len(contexts) - 1; i >= 0; i-- {
+		if e := after(hooks[i], contexts[i]); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package redisc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/go-redis/redis"
+)
+
+// contextHook records the value found under hookValueKey in the context of every hook call.
+type contextHook struct {
+	mu     sync.Mutex
+	values []interface{}
+}
+
+// hookValueKey is the context key of the value the contextHook looks for.
+type hookValueKey struct{}
+
+func (h *contextHook) record(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.values = append(h.values, ctx.Value(hookValueKey{}))
+}
+
+func (h *contextHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	h.record(ctx)
+	return ctx, nil
+}
+
+func (h *contextHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.record(ctx)
+	return nil
+}
+
+func (h *contextHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	h.record(ctx)
+	return ctx, nil
+}
+
+func (h *contextHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	h.record(ctx)
+	return nil
+}
+
+func TestHooksReceiveOperationContext(t *testing.T) {
+	tests := []struct {
+		name string
+		send func(client redis.UniversalClient)
+	}{
+		{"command", func(client redis.UniversalClient) { client.Get("a") }},
+		{"pipeline", func(client redis.UniversalClient) {
+			client.Pipelined(func(pipe redis.Pipeliner) error {
+				pipe.Get("a")
+				pipe.Get("b")
+				return nil
+			})
+		}},
+	}
+	for _, tt := range tests {
+		hook := &contextHook{}
+		d := NewClient(*NewSettings())
+		d.AddHook(hook)
+		client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+		d.instrument(client)
+
+		ctx := context.WithValue(context.Background(), hookValueKey{}, "operation")
+		bound := d.bind(ctx, client)
+		if err := await(ctx, func() error { tt.send(bound); return nil }); err != nil {
+			t.Fatalf("%s: await() error = %v", tt.name, err)
+		}
+		tt.send(client)
+		client.Close()
+
+		want := []interface{}{"operation", "operation", nil, nil}
+		if len(hook.values) != len(want) {
+			t.Fatalf("%s: hook called %d times, want %d", tt.name, len(hook.values), len(want))
+		}
+		for i := range want {
+			if hook.values[i] != want[i] {
+				t.Errorf("%s: call %d got context value %v, want %v", tt.name, i, hook.values[i], want[i])
+			}
+		}
+		n := 0
+		d.calls.Range(func(key, value interface{}) bool { n++; return true })
+		if n != 0 {
+			t.Errorf("%s: %d calls retained after the commands completed", tt.name, n)
+		}
+	}
+}
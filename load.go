@@ -0,0 +1,261 @@
+package redisc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/wrapify"
+)
+
+// unlockScript releases a lock taken by GetOrLoad, only if it is still held by the same owner.
+var unlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// GetOrLoad retrieves the value stored under key, loading and storing it with loader on a miss.
+// It is equivalent to GetOrLoadContext with a background context.
+func (c *Cache[T]) GetOrLoad(key string, ttl time.Duration, loader Loader[T]) (T, wrapify.R) {
+	return c.GetOrLoadContext(context.Background(), key, ttl, loader)
+}
+
+// GetOrLoadContext retrieves the value stored under key, loading it with loader and storing it for ttl
+// on a miss (cache-aside), giving up as soon as ctx expires or is cancelled.
+//
+// The concurrent misses of a key are coalesced in-process: a single call to loader is made and its outcome
+// is shared by every caller. As the load outlives the callers which give up, its context carries the values
+// of the context of the first caller but not its cancellation, and expires after the lock TTL, if set; each
+// caller only stops waiting for the load when its own ctx is done. An instance that waited in vain for
+// another one to store the value is given another lock TTL to load it itself. When a lock TTL is set (see
+// SetLockTTL), a lock is also taken in redis before loading, so that a single instance of the fleet
+// loads the value while the others wait for it to be stored, or for the lock to expire.
+//
+// When a stale TTL is set (see SetStaleTTL), the values are stored for ttl + the stale TTL, and a value
+// in the last stale TTL of its lifetime is served as is while it is refreshed in the background
+// (stale-while-revalidate).
+//
+// If the Datasource is not connected or the key cannot be retrieved, the value is loaded without being
+// cached. A stored value that cannot be decoded, e.g. after a change of T, is loaded again and overwritten.
+//
+// Returns:
+//   - the value and a 200 response carrying it if it has been retrieved or loaded;
+//   - the zero value and an error response if it could not be loaded.
+func (c *Cache[T]) GetOrLoadContext(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, wrapify.R) {
+	if _, _, ok := c.client(ctx); !ok {
+		return c.flights.do(ctx, key, c.lockTTL, func(ctx context.Context) (T, wrapify.R) {
+			return c.invoke(ctx, key, loader, false)
+		})
+	}
+	value, stale, found, response := c.lookup(ctx, key)
+	if found {
+		if stale {
+			go c.flights.do(ctx, key, c.lockTTL, func(ctx context.Context) (T, wrapify.R) {
+				return c.load(ctx, key, ttl, loader)
+			})
+		}
+		return value, response
+	}
+	if response.StatusCode() != 0 {
+		if ctx.Err() != nil {
+			return value, response
+		}
+		return c.flights.do(ctx, key, c.lockTTL, func(ctx context.Context) (T, wrapify.R) {
+			return c.invoke(ctx, key, loader, false)
+		})
+	}
+	return c.flights.do(ctx, key, c.lockTTL, func(ctx context.Context) (T, wrapify.R) {
+		return c.load(ctx, key, ttl, loader)
+	})
+}
+
+// lookup retrieves the value stored under key along with its remaining lifetime.
+//
+// Returns:
+//   - the value, whether it is stale, true and a 200 response if the key exists;
+//   - the zero value, false, false and an empty response if the key does not exist or its value cannot
+//     be decoded;
+//   - the zero value, false, false and an error response otherwise.
+func (c *Cache[T]) lookup(ctx context.Context, key string) (T, bool, bool, wrapify.R) {
+	var value T
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return value, false, false, response
+	}
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	err := await(ctx, func() error {
+		_, err := client.Pipelined(func(pipe redis.Pipeliner) error {
+			get = pipe.Get(c.key(key))
+			pttl = pipe.PTTL(c.key(key))
+			return nil
+		})
+		return err
+	})
+	if err == redis.Nil {
+		return value, false, false, wrapify.R{}
+	}
+	if err != nil {
+		return value, false, false, c.fail(ctx, err, "cache_get_or_load", key, "Failed to retrieve the key '%s'")
+	}
+	data, _ := get.Bytes()
+	if err := c.codec.Unmarshal(data, &value); err != nil {
+		// The value is reported as missing, so that it is loaded again and overwritten.
+		c.datasource.log(levelWarn, "The cached value could not be decoded and is loaded again", "key", key, "error", err)
+		var zero T
+		return zero, false, false, wrapify.R{}
+	}
+	remaining := pttl.Val()
+	stale := c.staleTTL > 0 && remaining >= 0 && remaining <= c.staleTTL
+	return value, stale, true, wrapify.WrapOk("", value).
+		WithMessagef("Successfully retrieved the key '%s'", key).
+		WithDebuggingKV("stale", stale).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// load loads the value of key with loader, under the redis lock if enabled, and stores it for ttl
+// (plus the stale TTL).
+func (c *Cache[T]) load(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, wrapify.R) {
+	if c.lockTTL > 0 {
+		token, acquired := c.lock(ctx, key)
+		if acquired {
+			defer c.unlock(ctx, key, token)
+		} else {
+			if value, ok := c.wait(ctx, key); ok {
+				return value, wrapify.WrapOk("", value).
+					WithMessagef("Successfully retrieved the key '%s' loaded by another instance", key).
+					WithHeader(wrapify.OK).
+					Reply()
+			}
+			// The wait has used up the time of ctx, which expires along with the lock: the value is
+			// loaded in place of the other instance on a context of its own, bounded by the lock TTL.
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), c.lockTTL)
+			defer cancel()
+		}
+	}
+	value, response := c.invoke(ctx, key, loader, true)
+	if !response.IsSuccess() {
+		return value, response
+	}
+	if ttl > 0 {
+		ttl += c.staleTTL
+	}
+	if err := c.SetContext(ctx, key, value, ttl).Cause(); err != nil {
+		c.datasource.log(levelWarn, "The loaded value could not be cached", "key", key, "error", err)
+	}
+	return value, response
+}
+
+// invoke calls loader for key and wraps its outcome into a response.
+func (c *Cache[T]) invoke(ctx context.Context, key string, loader Loader[T], cached bool) (T, wrapify.R) {
+	value, err := loader(ctx, key)
+	if err != nil {
+		return value, wrapify.WrapInternalServerError("", nil).
+			WithMessagef("Failed to load the key '%s'", key).
+			WithDebuggingKV("function", "cache_get_or_load").
+			WithDebuggingKV("key", key).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError).
+			Reply()
+	}
+	return value, wrapify.WrapOk("", value).
+		WithMessagef("Successfully loaded the key '%s'", key).
+		WithDebuggingKV("cached", cached).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// lock tries to take the redis lock guarding the load of key.
+//
+// Returns:
+//   - the token identifying the owner of the lock and true if it has been taken;
+//   - an empty token and false if it is held by another instance or could not be taken.
+func (c *Cache[T]) lock(ctx context.Context, key string) (string, bool) {
+	client, _, ok := c.client(ctx)
+	if !ok {
+		return "", false
+	}
+	token := randomID()
+	var acquired bool
+	err := await(ctx, func() error {
+		var err error
+		acquired, err = client.SetNX(c.lockKey(key), token, c.lockTTL).Result()
+		return err
+	})
+	return token, err == nil && acquired
+}
+
+// unlock releases the redis lock guarding the load of key, if it is still held by token.
+func (c *Cache[T]) unlock(ctx context.Context, key, token string) {
+	client, _, ok := c.client(ctx)
+	if !ok {
+		return
+	}
+	if err := unlockScript.Run(client, []string{c.lockKey(key)}, token).Err(); err != nil && err != redis.Nil {
+		c.datasource.log(levelWarn, "The cache lock could not be released", "key", key, "error", err)
+	}
+}
+
+// wait polls key until it is stored by the instance holding its lock, the lock expires or ctx is done.
+//
+// Returns:
+//   - the value and true if it has been stored in time;
+//   - the zero value and false otherwise, in which case the caller loads the value itself.
+func (c *Cache[T]) wait(ctx context.Context, key string) (T, bool) {
+	var zero T
+	deadline := time.NewTimer(c.lockTTL)
+	defer deadline.Stop()
+	ticker := time.NewTicker(defaultLockPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, false
+		case <-deadline.C:
+			return zero, false
+		case <-ticker.C:
+			if value, _, found, _ := c.lookup(ctx, key); found {
+				return value, true
+			}
+		}
+	}
+}
+
+// lockKey returns the redis key of the lock guarding the load of key.
+func (c *Cache[T]) lockKey(key string) string {
+	return c.key(key) + ":lock"
+}
+
+// do calls fn for key in a separate goroutine, unless a call for the same key is already in progress, then
+// awaits the outcome of the call, giving up as soon as ctx expires or is cancelled. The call keeps running
+// when its callers give up: fn receives a context carrying the values of ctx but not its cancellation,
+// which expires after timeout if positive.
+func (g *flightGroup[T]) do(ctx context.Context, key string, timeout time.Duration, fn func(ctx context.Context) (T, wrapify.R)) (T, wrapify.R) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall[T]{done: make(chan struct{})}
+		g.calls[key] = call
+		shared, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+		if timeout > 0 {
+			shared, cancel = context.WithTimeout(shared, timeout)
+		}
+		go func() {
+			defer func() {
+				cancel()
+				g.mu.Lock()
+				delete(g.calls, key)
+				g.mu.Unlock()
+				close(call.done)
+			}()
+			call.value, call.response = fn(shared)
+		}()
+	}
+	g.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.value, call.response
+	case <-ctx.Done():
+		var zero T
+		return zero, cancelledResponse(ctx.Err(), "The cache operation has been cancelled")
+	}
+}
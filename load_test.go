@@ -0,0 +1,221 @@
+package redisc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// valueKey is the context key of the value propagated to the shared calls.
+type valueKey struct{}
+
+func TestFlightGroupCoalesces(t *testing.T) {
+	g := &flightGroup[int]{calls: make(map[string]*flightCall[int])}
+	var calls int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = g.do(context.Background(), "k", 0, func(ctx context.Context) (int, wrapify.R) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return 42, wrapify.WrapOk("", 42).Reply()
+			})
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("fn called %d times, want 1", n)
+	}
+	for i, value := range results {
+		if value != 42 {
+			t.Errorf("caller %d got %d, want 42", i, value)
+		}
+	}
+}
+
+func TestFlightGroupOutlivesCallers(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr error
+	}{
+		{"unbounded", 0, nil},
+		{"bounded", 10 * time.Millisecond, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		g := &flightGroup[int]{calls: make(map[string]*flightCall[int])}
+		fn := func(ctx context.Context) (int, wrapify.R) {
+			if ctx.Value(valueKey{}) != "first" {
+				return 0, wrapify.WrapInternalServerError("", nil).WithErrSck(errors.New("values lost")).Reply()
+			}
+			select {
+			case <-ctx.Done():
+				return 0, wrapify.WrapRequestTimeout("", nil).WithErrSck(ctx.Err()).Reply()
+			case <-time.After(50 * time.Millisecond):
+				return 42, wrapify.WrapOk("", 42).Reply()
+			}
+		}
+
+		// The first caller gives up, the second one waits for the shared call.
+		first, cancel := context.WithCancel(context.WithValue(context.Background(), valueKey{}, "first"))
+		done := make(chan wrapify.R, 1)
+		go func() {
+			_, response := g.do(first, "k", tt.timeout, fn)
+			done <- response
+		}()
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+		if response := <-done; response.StatusCode() != http.StatusRequestTimeout {
+			t.Errorf("%s: first caller status = %d, want a request timeout", tt.name, response.StatusCode())
+		}
+		value, response := g.do(context.Background(), "k", tt.timeout, fn)
+		if tt.wantErr == nil {
+			if value != 42 || !response.IsSuccess() {
+				t.Errorf("%s: second caller got %d, %v, want 42", tt.name, value, response.Cause())
+			}
+		} else if !errors.Is(response.Cause(), tt.wantErr) {
+			t.Errorf("%s: second caller error = %v, want %v", tt.name, response.Cause(), tt.wantErr)
+		}
+	}
+}
+
+// counter returns a Loader loading "loaded:<key>" on behalf of the calls it counts, or the error of ctx.
+func counter(calls *int32) Loader[string] {
+	return func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(calls, 1)
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "loaded:" + key, nil
+	}
+}
+
+// newLoadingCache returns a Cache of strings on a fakeServer emulating the unlock script.
+func newLoadingCache(t *testing.T) (*fakeServer, *Cache[string]) {
+	s := newFakeServer(t)
+	s.Script(unlockScript.Hash(), func(s *fakeServer, keys, argv []string) interface{} {
+		if s.values[keys[0]] == argv[0] {
+			s.delete(keys[0])
+			return int64(1)
+		}
+		return int64(0)
+	})
+	return s, NewCache[string](newFakeDatasource(t, s), nil)
+}
+
+func TestGetOrLoad(t *testing.T) {
+	s, c := newLoadingCache(t)
+	c.SetLockTTL(time.Second)
+	var calls int32
+	for i := 0; i < 2; i++ {
+		value, response := c.GetOrLoad("k", time.Minute, counter(&calls))
+		if value != "loaded:k" || !response.IsSuccess() {
+			t.Fatalf("GetOrLoad() = %q, %v, want the loaded value", value, response.Cause())
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+	if value, ok := s.Get("k"); !ok || value != `"loaded:k"` {
+		t.Errorf("stored %q, %v, want the encoded value", value, ok)
+	}
+	if _, ok := s.Get("k:lock"); ok {
+		t.Error("lock not released")
+	}
+}
+
+func TestGetOrLoadLocked(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     bool
+		want      string
+		wantCalls int32
+	}{
+		{"stored by the lock holder", true, "remote", 0},
+		{"lock expired", false, "loaded:k", 1},
+	}
+	for _, tt := range tests {
+		s, c := newLoadingCache(t)
+		c.SetLockTTL(150 * time.Millisecond)
+		s.Set("k:lock", "other", 0)
+		if tt.store {
+			time.AfterFunc(50*time.Millisecond, func() { s.Set("k", `"remote"`, time.Minute) })
+		}
+		var calls int32
+		value, response := c.GetOrLoad("k", time.Minute, counter(&calls))
+		if value != tt.want || !response.IsSuccess() {
+			t.Errorf("%s: GetOrLoad() = %q, %v, want %q", tt.name, value, response.Cause(), tt.want)
+		}
+		if n := atomic.LoadInt32(&calls); n != tt.wantCalls {
+			t.Errorf("%s: loader called %d times, want %d", tt.name, n, tt.wantCalls)
+		}
+		if stored, _ := s.Get("k"); stored != `"`+tt.want+`"` {
+			t.Errorf("%s: stored %q, want %q", tt.name, stored, tt.want)
+		}
+	}
+}
+
+func TestGetOrLoadStale(t *testing.T) {
+	s, c := newLoadingCache(t)
+	c.SetStaleTTL(time.Minute)
+	s.Set("k", `"old"`, 30*time.Second)
+	var calls int32
+	value, response := c.GetOrLoad("k", time.Minute, counter(&calls))
+	if value != "old" || !response.IsSuccess() {
+		t.Fatalf("GetOrLoad() = %q, %v, want the stale value", value, response.Cause())
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if stored, _ := s.Get("k"); stored == `"loaded:k"` {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stale value not refreshed in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if value, _ := c.GetOrLoad("k", time.Minute, counter(&calls)); value != "loaded:k" {
+		t.Errorf("GetOrLoad() = %q after the refresh, want the loaded value", value)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+}
+
+func TestGetOrLoadDegrades(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(s *fakeServer)
+		stored string
+	}{
+		{"undecodable value reloaded", func(s *fakeServer) { s.Set("k", "{not json", 0) }, `"loaded:k"`},
+		{"redis failing", func(s *fakeServer) {
+			s.Set("k", `"cached"`, 0)
+			s.Fail("GET", "ERR failure")
+		}, `"cached"`},
+	}
+	for _, tt := range tests {
+		s, c := newLoadingCache(t)
+		tt.setup(s)
+		var calls int32
+		value, response := c.GetOrLoad("k", time.Minute, counter(&calls))
+		if value != "loaded:k" || !response.IsSuccess() {
+			t.Errorf("%s: GetOrLoad() = %q, %v, want the loaded value", tt.name, value, response.Cause())
+		}
+		if stored, _ := s.Get("k"); stored != tt.stored {
+			t.Errorf("%s: stored %q, want %q", tt.name, stored, tt.stored)
+		}
+	}
+}
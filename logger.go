@@ -0,0 +1,130 @@
+package redisc
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/sivaosorg/loggy"
+	"github.com/sivaosorg/wrapify"
+)
+
+// NewLoggyLogger returns the default Logger, which writes the log lines through the loggy package,
+// rendering the fields as key=value pairs after the message.
+func NewLoggyLogger() Logger {
+	return loggyLogger{}
+}
+
+// NewSlogLogger returns a Logger writing the log lines through the given log/slog Logger
+// (slog.Default() if nil), passing the fields as attributes.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return slogLogger{logger: logger}
+}
+
+func (loggyLogger) Debug(msg string, fields ...interface{}) {
+	loggy.Debugf("%s%s", msg, formatFields(fields))
+}
+
+func (loggyLogger) Info(msg string, fields ...interface{}) {
+	loggy.Infof("%s%s", msg, formatFields(fields))
+}
+
+func (loggyLogger) Warn(msg string, fields ...interface{}) {
+	loggy.Warnf("%s%s", msg, formatFields(fields))
+}
+
+func (loggyLogger) Error(msg string, fields ...interface{}) {
+	loggy.Errorf("%s%s", msg, formatFields(fields))
+}
+
+func (l slogLogger) Debug(msg string, fields ...interface{}) {
+	l.logger.Debug(msg, fields...)
+}
+
+func (l slogLogger) Info(msg string, fields ...interface{}) {
+	l.logger.Info(msg, fields...)
+}
+
+func (l slogLogger) Warn(msg string, fields ...interface{}) {
+	l.logger.Warn(msg, fields...)
+}
+
+func (l slogLogger) Error(msg string, fields ...interface{}) {
+	l.logger.Error(msg, fields...)
+}
+
+// log writes a log line at the given level through the logger of the Datasource.
+// Debug lines are only written when debugging is enabled in the Settings.
+func (d *Datasource) log(level logLevel, msg string, fields ...interface{}) {
+	if level == levelDebug && !d.conf.IsDebugging() {
+		return
+	}
+	logger := d.Logger()
+	switch level {
+	case levelDebug:
+		logger.Debug(msg, fields...)
+	case levelInfo:
+		logger.Info(msg, fields...)
+	case levelWarn:
+		logger.Warn(msg, fields...)
+	default:
+		logger.Error(msg, fields...)
+	}
+}
+
+// logResponse writes the message of the response at the given level, with the status code, the
+// debugging values of the response (e.g. redis_conn_str, executed_in) in alphabetical order, and
+// the error of the response, if any, as fields.
+func (d *Datasource) logResponse(level logLevel, response wrapify.R) {
+	if level == levelDebug && !d.conf.IsDebugging() {
+		return
+	}
+	fields := []interface{}{"status_code", response.StatusCode()}
+	debugging := response.Debugging()
+	keys := make([]string, 0, len(debugging))
+	for key := range debugging {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, key, debugging[key])
+	}
+	if err := response.Cause(); err != nil {
+		fields = append(fields, "error", err.Error())
+	}
+	d.log(level, response.Message(), fields...)
+}
+
+// levelOf returns the level at which an event of the given type is logged.
+func levelOf(t EventType) logLevel {
+	switch t {
+	case EventPingOK:
+		return levelDebug
+	case EventReconnected:
+		return levelInfo
+	case EventPingFailed, EventReconnectFailed:
+		return levelWarn
+	}
+	return levelError
+}
+
+// formatFields renders alternating keys and values as " key=value" pairs, quoting the values containing spaces.
+func formatFields(fields []interface{}) string {
+	var b strings.Builder
+	for i := 0; i < len(fields); i += 2 {
+		var value interface{} = "<missing>"
+		if i+1 < len(fields) {
+			value = fields[i+1]
+		}
+		s := fmt.Sprint(value)
+		if strings.ContainsAny(s, " \t\n\"") {
+			s = fmt.Sprintf("%q", s)
+		}
+		fmt.Fprintf(&b, " %v=%s", fields[i], s)
+	}
+	return b.String()
+}
@@ -0,0 +1,709 @@
+package redisc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redactedPassword replaces passwords in redacted Settings, mirroring String(true).
+const redactedPassword = "*****"
+
+// duration is a time.Duration that is (un)marshalled as a Go duration string (e.g. "30s").
+// When unmarshalling, a plain number is interpreted as a number of seconds.
+type duration time.Duration
+
+type settingsDTO struct {
+	Enabled         bool                `json:"enabled" yaml:"enabled"`
+	Debugging       bool                `json:"debugging" yaml:"debugging"`
+	Keepalive       bool                `json:"keepalive" yaml:"keepalive"`
+	PingInterval    duration            `json:"ping_interval" yaml:"ping_interval"`
+	SlowThreshold   duration            `json:"slow_threshold" yaml:"slow_threshold"`
+	SlowLogSize     int                 `json:"slow_log_size" yaml:"slow_log_size"`
+	Lazy            bool                `json:"lazy" yaml:"lazy"`
+	TransitionsOnly bool                `json:"transitions_only" yaml:"transitions_only"`
+	Conn            *connectionSettings `json:"conn" yaml:"conn"`
+	Retry           *retrySettings      `json:"retry" yaml:"retry"`
+	Timeout         *timeoutSettings    `json:"timeout" yaml:"timeout"`
+	Pool            *poolSettings       `json:"pool" yaml:"pool"`
+	Sentinel        *sentinelSettings   `json:"sentinel" yaml:"sentinel"`
+	Cluster         *clusterSettings    `json:"cluster" yaml:"cluster"`
+	TLS             *tlsSettings        `json:"tls" yaml:"tls"`
+	Reconnect       *reconnectSettings  `json:"reconnect" yaml:"reconnect"`
+	Tracking        *trackingSettings   `json:"tracking" yaml:"tracking"`
+}
+
+type connectionDTO struct {
+	Network  string `json:"network" yaml:"network"`
+	Addr     string `json:"addr" yaml:"addr"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	Database int    `json:"database" yaml:"database"`
+}
+
+type retryDTO struct {
+	MaxRetries      int      `json:"max_retries" yaml:"max_retries"`
+	MinRetryBackoff duration `json:"min_retry_backoff" yaml:"min_retry_backoff"`
+	MaxRetryBackoff duration `json:"max_retry_backoff" yaml:"max_retry_backoff"`
+}
+
+type timeoutDTO struct {
+	DialTimeout  duration `json:"dial_timeout" yaml:"dial_timeout"`
+	ReadTimeout  duration `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout duration `json:"write_timeout" yaml:"write_timeout"`
+}
+
+type poolDTO struct {
+	PoolSize           int      `json:"pool_size" yaml:"pool_size"`
+	MinIdleConns       int      `json:"min_idle_conns" yaml:"min_idle_conns"`
+	MaxConnAge         duration `json:"max_conn_age" yaml:"max_conn_age"`
+	PoolTimeout        duration `json:"pool_timeout" yaml:"pool_timeout"`
+	IdleTimeout        duration `json:"idle_timeout" yaml:"idle_timeout"`
+	IdleCheckFrequency duration `json:"idle_check_frequency" yaml:"idle_check_frequency"`
+}
+
+type sentinelDTO struct {
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	MasterName string   `json:"master_name,omitempty" yaml:"master_name,omitempty"`
+	Addrs      []string `json:"addrs,omitempty" yaml:"addrs,omitempty"`
+	Password   string   `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+type clusterDTO struct {
+	Enabled        bool     `json:"enabled" yaml:"enabled"`
+	Addrs          []string `json:"addrs,omitempty" yaml:"addrs,omitempty"`
+	ReadOnly       bool     `json:"read_only" yaml:"read_only"`
+	RouteByLatency bool     `json:"route_by_latency" yaml:"route_by_latency"`
+	RouteRandomly  bool     `json:"route_randomly" yaml:"route_randomly"`
+	MaxRedirects   int      `json:"max_redirects" yaml:"max_redirects"`
+}
+
+type tlsDTO struct {
+	Enabled            bool   `json:"enabled" yaml:"enabled"`
+	CAFile             string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty" yaml:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+	ServerName         string `json:"server_name,omitempty" yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	MinVersion         string `json:"min_version" yaml:"min_version"`
+}
+
+type reconnectDTO struct {
+	InitialDelay duration     `json:"initial_delay" yaml:"initial_delay"`
+	Multiplier   float64      `json:"multiplier" yaml:"multiplier"`
+	MaxDelay     duration     `json:"max_delay" yaml:"max_delay"`
+	Jitter       float64      `json:"jitter" yaml:"jitter"`
+	MaxAttempts  int          `json:"max_attempts" yaml:"max_attempts"`
+	GiveUp       GiveUpAction `json:"give_up" yaml:"give_up"`
+}
+
+type trackingDTO struct {
+	Enabled  bool         `json:"enabled" yaml:"enabled"`
+	Mode     TrackingMode `json:"mode" yaml:"mode"`
+	Prefixes []string     `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+}
+
+// mirror is implemented by the Settings and every sub-settings, which are (un)marshalled through
+// the struct D mirroring their unexported fields with exported, tagged ones.
+type mirror[D any] interface {
+	dto() D
+	apply(dto D) error
+}
+
+// Redacted returns a deep copy of the Settings in which every password is replaced by "*****",
+// mirroring String(true). Marshal the result to dump the effective configuration safely.
+func (c *Settings) Redacted() *Settings {
+	s := c.clone()
+	if s.conn != nil && s.conn.password != "" {
+		s.conn.password = redactedPassword
+	}
+	if s.sentinel != nil && s.sentinel.password != "" {
+		s.sentinel.password = redactedPassword
+	}
+	return s
+}
+
+// clone returns a deep copy of the Settings, which shares neither its sub-settings nor their slices.
+func (c *Settings) clone() *Settings {
+	s := *c
+	if c.conn != nil {
+		conn := *c.conn
+		s.conn = &conn
+	}
+	if c.retry != nil {
+		retry := *c.retry
+		s.retry = &retry
+	}
+	if c.timeout != nil {
+		timeout := *c.timeout
+		s.timeout = &timeout
+	}
+	if c.pool != nil {
+		pool := *c.pool
+		s.pool = &pool
+	}
+	if c.sentinel != nil {
+		sentinel := *c.sentinel
+		sentinel.addrs = cloneStrings(c.sentinel.addrs)
+		s.sentinel = &sentinel
+	}
+	if c.cluster != nil {
+		cluster := *c.cluster
+		cluster.addrs = cloneStrings(c.cluster.addrs)
+		s.cluster = &cluster
+	}
+	if c.tls != nil {
+		tls := *c.tls
+		s.tls = &tls
+	}
+	if c.reconnect != nil {
+		reconnect := *c.reconnect
+		s.reconnect = &reconnect
+	}
+	if c.tracking != nil {
+		tracking := *c.tracking
+		tracking.prefixes = cloneStrings(c.tracking.prefixes)
+		s.tracking = &tracking
+	}
+	return &s
+}
+
+// MarshalJSON encodes the Settings, including every sub-settings, as JSON with durations
+// rendered as Go duration strings. Use Redacted to hide the passwords. As for the sub-settings,
+// the receiver is a pointer: marshal a *Settings, not a Settings value.
+func (c *Settings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[settingsDTO](c)
+}
+
+// UnmarshalJSON decodes the Settings from JSON. Fields that are absent keep their current
+// value (or the defaults of NewSettings if unset), and unknown fields are rejected.
+// The sub-settings are replaced by decoded copies, so copies of the Settings are left unchanged.
+func (c *Settings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[settingsDTO](c, data)
+}
+
+// MarshalYAML encodes the Settings for YAML libraries that support the Marshaler interface
+// (e.g. gopkg.in/yaml.v2 and gopkg.in/yaml.v3).
+func (c *Settings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[settingsDTO](c)
+}
+
+// UnmarshalYAML decodes the Settings for YAML libraries that support the Unmarshaler interface.
+// Fields that are absent keep their current value (or the defaults of NewSettings if unset),
+// and unknown fields are rejected.
+// The sub-settings are replaced by decoded copies, so copies of the Settings are left unchanged.
+func (c *Settings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[settingsDTO](c, unmarshal)
+}
+
+// dto mirrors the Settings. The sub-settings it points to are deep copies of the current ones,
+// assigning the defaults of NewSettings to the unset ones, so that decoding into them leaves
+// the Settings unchanged until apply.
+func (c *Settings) dto() settingsDTO {
+	s := c.clone().withDefaults()
+	return settingsDTO{
+		Enabled:         s.enabled,
+		Debugging:       s.debugging,
+		Keepalive:       s.keepalive,
+		PingInterval:    duration(s.pingInterval),
+		SlowThreshold:   duration(s.slowThreshold),
+		SlowLogSize:     s.slowLogSize,
+		Lazy:            s.lazy,
+		TransitionsOnly: s.transitionsOnly,
+		Conn:            s.conn,
+		Retry:           s.retry,
+		Timeout:         s.timeout,
+		Pool:            s.pool,
+		Sentinel:        s.sentinel,
+		Cluster:         s.cluster,
+		TLS:             s.tls,
+		Reconnect:       s.reconnect,
+		Tracking:        s.tracking,
+	}
+}
+
+func (c *Settings) apply(dto settingsDTO) error {
+	c.enabled = dto.Enabled
+	c.debugging = dto.Debugging
+	c.keepalive = dto.Keepalive
+	c.pingInterval = time.Duration(dto.PingInterval)
+	c.slowThreshold = time.Duration(dto.SlowThreshold)
+	c.slowLogSize = dto.SlowLogSize
+	c.lazy = dto.Lazy
+	c.transitionsOnly = dto.TransitionsOnly
+	// A sub-settings decoded as null is reset to the defaults.
+	c.conn = dto.Conn
+	c.retry = dto.Retry
+	c.timeout = dto.Timeout
+	c.pool = dto.Pool
+	c.sentinel = dto.Sentinel
+	c.cluster = dto.Cluster
+	c.tls = dto.TLS
+	c.reconnect = dto.Reconnect
+	c.tracking = dto.Tracking
+	c.withDefaults()
+	return nil
+}
+
+// withDefaults assigns the defaults of NewSettings to every unset sub-settings and returns the Settings.
+func (c *Settings) withDefaults() *Settings {
+	return c.
+		SetConn(c.conn).
+		SetRetry(c.retry).
+		SetTimeout(c.timeout).
+		SetPool(c.pool).
+		SetSentinel(c.sentinel).
+		SetCluster(c.cluster).
+		SetTLS(c.tls).
+		SetReconnect(c.reconnect).
+		SetTracking(c.tracking)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Marshalling the sub-settings
+//_______________________________________________________________________
+
+func (c *connectionSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[connectionDTO](c)
+}
+
+func (c *connectionSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[connectionDTO](c, data)
+}
+
+func (c *connectionSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[connectionDTO](c)
+}
+
+func (c *connectionSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[connectionDTO](c, unmarshal)
+}
+
+func (r *retrySettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[retryDTO](r)
+}
+
+func (r *retrySettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[retryDTO](r, data)
+}
+
+func (r *retrySettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[retryDTO](r)
+}
+
+func (r *retrySettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[retryDTO](r, unmarshal)
+}
+
+func (t *timeoutSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[timeoutDTO](t)
+}
+
+func (t *timeoutSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[timeoutDTO](t, data)
+}
+
+func (t *timeoutSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[timeoutDTO](t)
+}
+
+func (t *timeoutSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[timeoutDTO](t, unmarshal)
+}
+
+func (p *poolSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[poolDTO](p)
+}
+
+func (p *poolSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[poolDTO](p, data)
+}
+
+func (p *poolSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[poolDTO](p)
+}
+
+func (p *poolSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[poolDTO](p, unmarshal)
+}
+
+func (s *sentinelSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[sentinelDTO](s)
+}
+
+func (s *sentinelSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[sentinelDTO](s, data)
+}
+
+func (s *sentinelSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[sentinelDTO](s)
+}
+
+func (s *sentinelSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[sentinelDTO](s, unmarshal)
+}
+
+func (c *clusterSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[clusterDTO](c)
+}
+
+func (c *clusterSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[clusterDTO](c, data)
+}
+
+func (c *clusterSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[clusterDTO](c)
+}
+
+func (c *clusterSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[clusterDTO](c, unmarshal)
+}
+
+func (t *tlsSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[tlsDTO](t)
+}
+
+func (t *tlsSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[tlsDTO](t, data)
+}
+
+func (t *tlsSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[tlsDTO](t)
+}
+
+func (t *tlsSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[tlsDTO](t, unmarshal)
+}
+
+func (r *reconnectSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[reconnectDTO](r)
+}
+
+func (r *reconnectSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[reconnectDTO](r, data)
+}
+
+func (r *reconnectSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[reconnectDTO](r)
+}
+
+func (r *reconnectSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[reconnectDTO](r, unmarshal)
+}
+
+func (t *trackingSettings) MarshalJSON() ([]byte, error) {
+	return marshalJSON[trackingDTO](t)
+}
+
+func (t *trackingSettings) UnmarshalJSON(data []byte) error {
+	return unmarshalJSON[trackingDTO](t, data)
+}
+
+func (t *trackingSettings) MarshalYAML() (interface{}, error) {
+	return marshalYAML[trackingDTO](t)
+}
+
+func (t *trackingSettings) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAML[trackingDTO](t, unmarshal)
+}
+
+func (c *connectionSettings) dto() connectionDTO {
+	return connectionDTO{
+		Network:  c.network,
+		Addr:     c.connectionStrings,
+		Username: c.username,
+		Password: c.password,
+		Database: c.database,
+	}
+}
+
+func (c *connectionSettings) apply(dto connectionDTO) error {
+	c.network = dto.Network
+	c.connectionStrings = dto.Addr
+	c.username = dto.Username
+	c.password = dto.Password
+	c.database = dto.Database
+	return nil
+}
+
+func (r *retrySettings) dto() retryDTO {
+	return retryDTO{
+		MaxRetries:      r.maxRetries,
+		MinRetryBackoff: duration(r.minRetryBackoff),
+		MaxRetryBackoff: duration(r.maxRetryBackoff),
+	}
+}
+
+func (r *retrySettings) apply(dto retryDTO) error {
+	r.maxRetries = dto.MaxRetries
+	r.minRetryBackoff = time.Duration(dto.MinRetryBackoff)
+	r.maxRetryBackoff = time.Duration(dto.MaxRetryBackoff)
+	return nil
+}
+
+func (t *timeoutSettings) dto() timeoutDTO {
+	return timeoutDTO{
+		DialTimeout:  duration(t.connTimeout),
+		ReadTimeout:  duration(t.readTimeout),
+		WriteTimeout: duration(t.writeTimeout),
+	}
+}
+
+func (t *timeoutSettings) apply(dto timeoutDTO) error {
+	t.connTimeout = time.Duration(dto.DialTimeout)
+	t.readTimeout = time.Duration(dto.ReadTimeout)
+	t.writeTimeout = time.Duration(dto.WriteTimeout)
+	return nil
+}
+
+func (p *poolSettings) dto() poolDTO {
+	return poolDTO{
+		PoolSize:           p.poolSize,
+		MinIdleConns:       p.minIdleConn,
+		MaxConnAge:         duration(p.maxConnAge),
+		PoolTimeout:        duration(p.poolTimeout),
+		IdleTimeout:        duration(p.idleTimeout),
+		IdleCheckFrequency: duration(p.idleCheckFrequency),
+	}
+}
+
+func (p *poolSettings) apply(dto poolDTO) error {
+	p.poolSize = dto.PoolSize
+	p.minIdleConn = dto.MinIdleConns
+	p.maxConnAge = time.Duration(dto.MaxConnAge)
+	p.poolTimeout = time.Duration(dto.PoolTimeout)
+	p.idleTimeout = time.Duration(dto.IdleTimeout)
+	p.idleCheckFrequency = time.Duration(dto.IdleCheckFrequency)
+	return nil
+}
+
+func (s *sentinelSettings) dto() sentinelDTO {
+	return sentinelDTO{
+		Enabled:    s.enabled,
+		MasterName: s.masterName,
+		Addrs:      cloneStrings(s.addrs),
+		Password:   s.password,
+	}
+}
+
+func (s *sentinelSettings) apply(dto sentinelDTO) error {
+	s.enabled = dto.Enabled
+	s.masterName = dto.MasterName
+	s.addrs = dto.Addrs
+	s.password = dto.Password
+	return nil
+}
+
+func (c *clusterSettings) dto() clusterDTO {
+	return clusterDTO{
+		Enabled:        c.enabled,
+		Addrs:          cloneStrings(c.addrs),
+		ReadOnly:       c.readOnly,
+		RouteByLatency: c.routeByLatency,
+		RouteRandomly:  c.routeRandomly,
+		MaxRedirects:   c.maxRedirects,
+	}
+}
+
+func (c *clusterSettings) apply(dto clusterDTO) error {
+	c.enabled = dto.Enabled
+	c.addrs = dto.Addrs
+	c.readOnly = dto.ReadOnly
+	c.routeByLatency = dto.RouteByLatency
+	c.routeRandomly = dto.RouteRandomly
+	c.maxRedirects = dto.MaxRedirects
+	return nil
+}
+
+func (t *tlsSettings) dto() tlsDTO {
+	return tlsDTO{
+		Enabled:            t.enabled,
+		CAFile:             t.caFile,
+		CertFile:           t.certFile,
+		KeyFile:            t.keyFile,
+		ServerName:         t.serverName,
+		InsecureSkipVerify: t.insecureSkipVerify,
+		MinVersion:         formatTLSVersion(t.minVersion),
+	}
+}
+
+func (t *tlsSettings) apply(dto tlsDTO) error {
+	version, err := parseTLSVersion(dto.MinVersion)
+	if err != nil {
+		return fmt.Errorf("min_version %v", err)
+	}
+	t.enabled = dto.Enabled
+	t.caFile = dto.CAFile
+	t.certFile = dto.CertFile
+	t.keyFile = dto.KeyFile
+	t.serverName = dto.ServerName
+	t.insecureSkipVerify = dto.InsecureSkipVerify
+	t.minVersion = version
+	return nil
+}
+
+func (r *reconnectSettings) dto() reconnectDTO {
+	return reconnectDTO{
+		InitialDelay: duration(r.initialDelay),
+		Multiplier:   r.multiplier,
+		MaxDelay:     duration(r.maxDelay),
+		Jitter:       r.jitter,
+		MaxAttempts:  r.maxAttempts,
+		GiveUp:       r.giveUp,
+	}
+}
+
+func (r *reconnectSettings) apply(dto reconnectDTO) error {
+	r.initialDelay = time.Duration(dto.InitialDelay)
+	r.multiplier = dto.Multiplier
+	r.maxDelay = time.Duration(dto.MaxDelay)
+	r.jitter = dto.Jitter
+	r.maxAttempts = dto.MaxAttempts
+	r.giveUp = dto.GiveUp
+	return nil
+}
+
+func (t *trackingSettings) dto() trackingDTO {
+	return trackingDTO{
+		Enabled:  t.enabled,
+		Mode:     t.mode,
+		Prefixes: cloneStrings(t.prefixes),
+	}
+}
+
+func (t *trackingSettings) apply(dto trackingDTO) error {
+	t.enabled = dto.Enabled
+	t.mode = dto.Mode
+	t.prefixes = dto.Prefixes
+	return nil
+}
+
+// marshalJSON encodes the mirror of m as JSON.
+func marshalJSON[D any](m mirror[D]) ([]byte, error) {
+	return json.Marshal(m.dto())
+}
+
+// unmarshalJSON decodes data into the mirror of m, rejecting the unknown fields, and applies it to m.
+// Fields that are absent keep their current value.
+func unmarshalJSON[D any](m mirror[D], data []byte) error {
+	dto := m.dto()
+	if err := unmarshalJSONStrict(data, &dto); err != nil {
+		return err
+	}
+	return m.apply(dto)
+}
+
+// marshalYAML returns the mirror of m for YAML libraries to encode.
+func marshalYAML[D any](m mirror[D]) (interface{}, error) {
+	return m.dto(), nil
+}
+
+// unmarshalYAML decodes the YAML node behind unmarshal into the mirror of m, rejecting the unknown
+// fields, and applies it to m. Fields that are absent keep their current value.
+func unmarshalYAML[D any](m mirror[D], unmarshal func(interface{}) error) error {
+	dto := m.dto()
+	if err := unmarshalYAMLStrict(unmarshal, &dto); err != nil {
+		return err
+	}
+	return m.apply(dto)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Marshalling duration
+//_______________________________________________________________________
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(formatDuration(time.Duration(d)))
+}
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return d.set(value, string(data))
+}
+
+func (d duration) MarshalYAML() (interface{}, error) {
+	return formatDuration(time.Duration(d)), nil
+}
+
+func (d *duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return d.set(value, fmt.Sprint(value))
+}
+
+// set assigns the duration decoded as value, either a Go duration string or a number of seconds,
+// quoting raw in the error returned if value is neither.
+func (d *duration) set(value interface{}, raw string) error {
+	var seconds float64
+	switch v := value.(type) {
+	case string:
+		parsed, err := parseDuration(v)
+		if err != nil {
+			return fmt.Errorf("duration %v", err)
+		}
+		*d = duration(parsed)
+		return nil
+	case float64:
+		seconds = v
+	case int:
+		seconds = float64(v)
+	case int64:
+		seconds = float64(v)
+	case uint64:
+		seconds = float64(v)
+	default:
+		return fmt.Errorf("duration is neither a string nor a number: %s", raw)
+	}
+	*d = duration(time.Duration(seconds * float64(time.Second)))
+	return nil
+}
+
+// cloneStrings returns a copy of values, nil if values is nil.
+func cloneStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string(nil), values...)
+}
+
+// unmarshalJSONStrict decodes data into v, rejecting the fields that v does not declare.
+func unmarshalJSONStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// unmarshalYAMLStrict decodes the YAML node behind unmarshal into v, rejecting the fields
+// that are not declared by the yaml tags of the struct v points to.
+func unmarshalYAMLStrict(unmarshal func(interface{}) error, v interface{}) error {
+	var fields map[string]interface{}
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		known[name] = true
+	}
+	var unknown []string
+	for name := range fields {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("yaml: unknown field(s) %s", strings.Join(unknown, ", "))
+	}
+	return unmarshal(v)
+}
@@ -0,0 +1,199 @@
+package redisc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeYAML returns an unmarshal function standing for a YAML library decoding doc,
+// which is converted through JSON for lack of a YAML dependency.
+func fakeYAML(doc map[string]interface{}) func(interface{}) error {
+	return func(v interface{}) error {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+	}
+}
+
+func TestSettingsJSONRoundTrip(t *testing.T) {
+	s := NewSettings().SetPingInterval(90 * time.Second)
+	s.Conn().SetConnectionStrings("127.0.0.1:6380").SetPassword("secret")
+	s.Pool().SetPoolSize(50).SetIdleTimeout(2 * time.Hour)
+	s.Timeout().SetReadTimeout(500 * time.Millisecond)
+	s.Retry().SetMinRetryBackoff(1500 * time.Millisecond)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"ping_interval":"1m30s"`, `"idle_timeout":"2h"`, `"read_timeout":"500ms"`, `"min_retry_backoff":"1.5s"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Marshal() = %s, missing %s", data, want)
+		}
+	}
+	var decoded Settings
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	again, err := json.Marshal(&decoded)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("round-trip changed the settings:\n%s\n%s", data, again)
+	}
+	if decoded.pingInterval != 90*time.Second || decoded.pool.idleTimeout != 2*time.Hour {
+		t.Errorf("durations = %v, %v, want 1m30s and 2h", decoded.pingInterval, decoded.pool.idleTimeout)
+	}
+}
+
+func TestSettingsJSONPartial(t *testing.T) {
+	var s Settings
+	if err := json.Unmarshal([]byte(`{"pool":{"pool_size":50}}`), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	defaults := NewSettings()
+	if s.pool.poolSize != 50 {
+		t.Errorf("pool size = %d, want 50", s.pool.poolSize)
+	}
+	if s.pool.minIdleConn != defaults.pool.minIdleConn || s.pingInterval != defaults.pingInterval {
+		t.Errorf("absent fields do not keep the defaults: min idle %d, ping interval %v", s.pool.minIdleConn, s.pingInterval)
+	}
+}
+
+func TestSettingsJSONUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"top level", `{"enabled":true,"pool_size":10}`, `unknown field "pool_size"`},
+		{"nested", `{"pool":{"size":10}}`, `unknown field "size"`},
+		{"nested after a known field", `{"sentinel":{"enabled":true,"master":"mymaster"}}`, `unknown field "master"`},
+	}
+	for _, tt := range tests {
+		var s Settings
+		err := json.Unmarshal([]byte(tt.data), &s)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Unmarshal(%s) error = %v, want %q", tt.name, tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    time.Duration
+		wantErr string
+	}{
+		{`"3s"`, 3 * time.Second, ""},
+		{`"1m30s"`, 90 * time.Second, ""},
+		{`"90"`, 90 * time.Second, ""},
+		{`3`, 3 * time.Second, ""},
+		{`1.5`, 1500 * time.Millisecond, ""},
+		{`"soon"`, 0, "duration is not a valid duration: 'soon'"},
+		{`true`, 0, "duration is neither a string nor a number: true"},
+	}
+	for _, tt := range tests {
+		var d duration
+		err := json.Unmarshal([]byte(tt.data), &d)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Unmarshal(%s) error = %v, want %q", tt.data, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || time.Duration(d) != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", tt.data, time.Duration(d), err, tt.want)
+			continue
+		}
+		data, _ := json.Marshal(d)
+		var again duration
+		if err := json.Unmarshal(data, &again); err != nil || again != d {
+			t.Errorf("duration %v does not round-trip through %s", time.Duration(d), data)
+		}
+	}
+}
+
+func TestPoolSettingsYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		wantErr string
+	}{
+		{"known fields", map[string]interface{}{"pool_size": 50, "idle_timeout": "2m", "pool_timeout": "1m"}, ""},
+		{"unknown fields", map[string]interface{}{"pool_size": 50, "extra": 1, "bogus": true}, "yaml: unknown field(s) bogus, extra"},
+	}
+	for _, tt := range tests {
+		p := NewPoolSettings()
+		err := p.UnmarshalYAML(fakeYAML(tt.doc))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: UnmarshalYAML() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: UnmarshalYAML() error = %v", tt.name, err)
+		}
+		if p.poolSize != 50 || p.idleTimeout != 2*time.Minute || p.poolTimeout != time.Minute {
+			t.Errorf("%s: decoded %d, %v, %v", tt.name, p.poolSize, p.idleTimeout, p.poolTimeout)
+		}
+		if p.minIdleConn != NewPoolSettings().minIdleConn {
+			t.Errorf("%s: min idle conns = %d, want the default kept", tt.name, p.minIdleConn)
+		}
+		out, err := p.MarshalYAML()
+		if err != nil {
+			t.Fatalf("%s: MarshalYAML() error = %v", tt.name, err)
+		}
+		if dto := out.(poolDTO); time.Duration(dto.IdleTimeout) != 2*time.Minute {
+			t.Errorf("%s: MarshalYAML() idle timeout = %v", tt.name, time.Duration(dto.IdleTimeout))
+		}
+	}
+}
+
+func TestSettingsUnmarshalKeepsCopies(t *testing.T) {
+	s := NewSettings()
+	s.Pool().SetPoolSize(10)
+	s.Cluster().SetAddrs([]string{"a:7000", "b:7001"})
+	copied := *s
+	if err := json.Unmarshal([]byte(`{"pool":{"pool_size":50},"cluster":{"addrs":["c:7002"]}}`), s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if s.pool.poolSize != 50 || len(s.cluster.addrs) != 1 {
+		t.Errorf("decoded pool size %d, cluster addrs %q", s.pool.poolSize, s.cluster.addrs)
+	}
+	if copied.pool.poolSize != 10 || copied.cluster.addrs[0] != "a:7000" || len(copied.cluster.addrs) != 2 {
+		t.Errorf("copy changed: pool size %d, cluster addrs %q", copied.pool.poolSize, copied.cluster.addrs)
+	}
+}
+
+func TestDurationYAML(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{"250ms", 250 * time.Millisecond, false},
+		{"45", 45 * time.Second, false},
+		{45, 45 * time.Second, false},
+		{uint64(2), 2 * time.Second, false},
+		{0.5, 500 * time.Millisecond, false},
+		{"later", 0, true},
+		{true, 0, true},
+	}
+	for _, tt := range tests {
+		var d duration
+		err := d.UnmarshalYAML(func(v interface{}) error {
+			*v.(*interface{}) = tt.value
+			return nil
+		})
+		if (err != nil) != tt.wantErr || time.Duration(d) != tt.want {
+			t.Errorf("UnmarshalYAML(%v) = %v, %v, want %v", tt.value, time.Duration(d), err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package redisc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/unify4g"
+)
+
+// NewCollector creates a Collector rendering the metrics under the "redisc" namespace,
+// e.g. redisc_up or redisc_commands_total. Register the Datasources to expose with Register.
+func NewCollector() *Collector {
+	return &Collector{namespace: defaultMetricsNamespace}
+}
+
+// Register adds the Datasource to the Collector; its metrics are labelled with datasource="<name>".
+// It returns the Collector for method chaining.
+func (c *Collector) Register(name string, d *Datasource) *Collector {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources = append(c.sources, collectorSource{name: name, datasource: d})
+	return c
+}
+
+// ServeHTTP renders the metrics of the registered Datasources in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics of the registered Datasources to w in the Prometheus text exposition format.
+// The metrics exposed for each Datasource are:
+//   - up, reconnect_attempts: the connection status and the current number of failed reconnection attempts;
+//   - pool_*: the connection pool statistics reported by PoolStats;
+//   - ping_duration_seconds, ping_failures_total: the outcome of the keepalive pings;
+//   - reconnects_total: the reconnections, labelled with result="success" or result="failure";
+//   - commands_total, command_errors_total, command_duration_seconds: the executed commands, by command name,
+//     including the commands sent in pipelines, which are timed with the duration of the whole pipeline;
+//   - events_dropped_total: the events dropped because the buffer of a Subscription was full.
+//
+// Returns:
+//   - the number of bytes written;
+//   - the first error reported by w.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.RLock()
+	namespace, sources := c.namespace, append([]collectorSource(nil), c.sources...)
+	c.mu.RUnlock()
+
+	snapshots := make([]metricsSnapshot, len(sources))
+	for i, source := range sources {
+		snapshots[i] = source.datasource.snapshot(source.name)
+	}
+	e := &exposition{w: bufio.NewWriter(w), namespace: namespace}
+	e.family("up", "gauge", "Whether the datasource is connected (1) or not (0).")
+	for _, s := range snapshots {
+		e.sample("up", s.labels(), boolValue(s.up))
+	}
+	e.family("reconnect_attempts", "gauge", "The current number of consecutive failed reconnection attempts.")
+	for _, s := range snapshots {
+		e.sample("reconnect_attempts", s.labels(), float64(s.attempts))
+	}
+	pool := []struct {
+		name, kind, help string
+		value            func(p *redis.PoolStats) uint32
+	}{
+		{"pool_hits_total", "counter", "The number of times a free connection was found in the pool.", func(p *redis.PoolStats) uint32 { return p.Hits }},
+		{"pool_misses_total", "counter", "The number of times a free connection was not found in the pool.", func(p *redis.PoolStats) uint32 { return p.Misses }},
+		{"pool_timeouts_total", "counter", "The number of times a wait for a pool connection timed out.", func(p *redis.PoolStats) uint32 { return p.Timeouts }},
+		{"pool_total_conns", "gauge", "The number of connections in the pool.", func(p *redis.PoolStats) uint32 { return p.TotalConns }},
+		{"pool_idle_conns", "gauge", "The number of idle connections in the pool.", func(p *redis.PoolStats) uint32 { return p.IdleConns }},
+		{"pool_stale_conns_total", "counter", "The number of stale connections removed from the pool.", func(p *redis.PoolStats) uint32 { return p.StaleConns }},
+	}
+	for _, m := range pool {
+		e.family(m.name, m.kind, m.help)
+		for _, s := range snapshots {
+			if s.pool != nil {
+				e.sample(m.name, s.labels(), float64(m.value(s.pool)))
+			}
+		}
+	}
+	e.family("ping_duration_seconds", "histogram", "The round-trip time of the successful keepalive pings.")
+	for _, s := range snapshots {
+		e.histogram("ping_duration_seconds", s.labels(), s.pings)
+	}
+	e.family("ping_failures_total", "counter", "The number of failed keepalive pings.")
+	for _, s := range snapshots {
+		e.sample("ping_failures_total", s.labels(), float64(s.pingFailures))
+	}
+	e.family("reconnects_total", "counter", "The number of reconnection attempts, by result.")
+	for _, s := range snapshots {
+		e.sample("reconnects_total", s.labels("result", "success"), float64(s.reconnects))
+		e.sample("reconnects_total", s.labels("result", "failure"), float64(s.reconnectFailures))
+	}
+	e.family("commands_total", "counter", "The number of executed commands, by command name.")
+	for _, s := range snapshots {
+		for _, name := range s.commandNames() {
+			e.sample("commands_total", s.labels("command", name), float64(s.commands[name].calls))
+		}
+	}
+	e.family("command_errors_total", "counter", "The number of failed commands, by command name.")
+	for _, s := range snapshots {
+		for _, name := range s.commandNames() {
+			e.sample("command_errors_total", s.labels("command", name), float64(s.commands[name].errors))
+		}
+	}
+	e.family("command_duration_seconds", "histogram", "The duration of the executed commands, by command name.")
+	for _, s := range snapshots {
+		for _, name := range s.commandNames() {
+			e.histogram("command_duration_seconds", s.labels("command", name), s.commands[name].latency)
+		}
+	}
+	e.family("events_dropped_total", "counter", "The number of events dropped because the buffer of a subscription was full.")
+	for _, s := range snapshots {
+		e.sample("events_dropped_total", s.labels(), float64(s.dropped))
+	}
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.n, e.err
+}
+
+// metricsSnapshot is a consistent copy of the metrics of a Datasource, taken for rendering.
+type metricsSnapshot struct {
+	name              string
+	up                bool
+	attempts          int
+	pool              *redis.PoolStats
+	pings             histogram
+	pingFailures      uint64
+	reconnects        uint64
+	reconnectFailures uint64
+	commands          map[string]commandMetrics
+	dropped           uint64
+}
+
+// snapshot copies the metrics of the Datasource, labelling them with the given name.
+func (d *Datasource) snapshot(name string) metricsSnapshot {
+	s := metricsSnapshot{
+		name:     name,
+		up:       d.IsConnected(),
+		attempts: d.ReconnectAttempts(),
+		dropped:  d.DroppedEvents(),
+	}
+	if conn := d.Conn(); conn != nil {
+		s.pool = conn.PoolStats()
+	} else if cluster := d.ClusterConn(); cluster != nil {
+		s.pool = cluster.PoolStats()
+	}
+	m := &d.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.pings = m.pings.clone()
+	s.pingFailures = m.pingFailures
+	s.reconnects = m.reconnects
+	s.reconnectFailures = m.reconnectFailures
+	s.commands = make(map[string]commandMetrics, len(m.commands))
+	for name, command := range m.commands {
+		s.commands[name] = commandMetrics{calls: command.calls, errors: command.errors, latency: command.latency.clone()}
+	}
+	return s
+}
+
+// labels returns the labels of the samples of the snapshot: the datasource label,
+// followed by the given name/value pairs.
+func (s metricsSnapshot) labels(pairs ...string) []string {
+	return append([]string{"datasource", s.name}, pairs...)
+}
+
+// commandNames returns the names of the recorded commands, in alphabetical order.
+func (s metricsSnapshot) commandNames() []string {
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// observePing records the outcome of a keepalive ping.
+func (m *metrics) observePing(duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.pingFailures++
+		return
+	}
+	m.pings.observe(duration)
+}
+
+// observeReconnect records the outcome of a reconnection attempt.
+func (m *metrics) observeReconnect(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.reconnectFailures++
+		return
+	}
+	m.reconnects++
+}
+
+// observeCommand records the execution of a command. A redis.Nil reply is not counted as an error.
+func (m *metrics) observeCommand(name string, duration time.Duration, err error) {
+	if unify4g.IsEmpty(name) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.commands == nil {
+		m.commands = make(map[string]*commandMetrics)
+	}
+	command, ok := m.commands[name]
+	if !ok {
+		command = &commandMetrics{}
+		m.commands[name] = command
+	}
+	command.calls++
+	if err != nil && err != redis.Nil {
+		command.errors++
+	}
+	command.latency.observe(duration)
+}
+
+// observe records a duration in the histogram, using defaultLatencyBuckets if no bucket is defined.
+func (h *histogram) observe(duration time.Duration) {
+	if h.bounds == nil {
+		h.bounds = defaultLatencyBuckets
+		h.counts = make([]uint64, len(h.bounds))
+	}
+	seconds := duration.Seconds()
+	if i := sort.SearchFloat64s(h.bounds, seconds); i < len(h.bounds) {
+		h.counts[i]++
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// clone returns a copy of the histogram that does not share its buckets.
+func (h histogram) clone() histogram {
+	h.counts = append([]uint64(nil), h.counts...)
+	return h
+}
+
+// exposition writes metric families in the Prometheus text exposition format,
+// keeping track of the bytes written and of the first error.
+type exposition struct {
+	w         *bufio.Writer
+	namespace string
+	n         int64
+	err       error
+}
+
+// family writes the HELP and TYPE lines of a metric family.
+func (e *exposition) family(name, kind, help string) {
+	e.printf("# HELP %s %s\n# TYPE %s %s\n", e.name(name), help, e.name(name), kind)
+}
+
+// sample writes a sample of the given metric, labelled with the given name/value pairs.
+func (e *exposition) sample(name string, labels []string, value float64) {
+	e.printf("%s%s %s\n", e.name(name), formatLabels(labels), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// histogram writes the cumulative buckets, sum and count samples of a histogram. A histogram
+// without observation is rendered with the defaultLatencyBuckets bounds.
+func (e *exposition) histogram(name string, labels []string, h histogram) {
+	bounds := h.bounds
+	if bounds == nil {
+		bounds = defaultLatencyBuckets
+	}
+	var cumulative uint64
+	for i, bound := range bounds {
+		if i < len(h.counts) {
+			cumulative += h.counts[i]
+		}
+		e.sample(name+"_bucket", append(labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)), float64(cumulative))
+	}
+	e.sample(name+"_bucket", append(labels, "le", "+Inf"), float64(h.count))
+	e.sample(name+"_sum", labels, h.sum)
+	e.sample(name+"_count", labels, float64(h.count))
+}
+
+// name returns the full name of a metric, prefixed with the namespace.
+func (e *exposition) name(name string) string {
+	if unify4g.IsEmpty(e.namespace) {
+		return name
+	}
+	return e.namespace + "_" + name
+}
+
+func (e *exposition) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(e.w, format, args...)
+	e.n += int64(n)
+	e.err = err
+}
+
+// formatLabels renders the given name/value pairs as a Prometheus label set, e.g. {datasource="primary"}.
+func formatLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(replacer.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// boolValue returns 1 if b is true, or 0 otherwise.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,142 @@
+package redisc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+func TestCollectorWriteTo(t *testing.T) {
+	d := NewClient(*NewSettings())
+	d.metrics.observePing(2*time.Millisecond, nil)
+	d.metrics.observePing(0, errors.New("timeout"))
+	d.metrics.observeReconnect(nil)
+	d.metrics.observeReconnect(errors.New("refused"))
+	d.metrics.observeReconnect(errors.New("refused"))
+	d.metrics.observeCommand("get", 300*time.Microsecond, nil)
+	d.metrics.observeCommand("get", 3*time.Millisecond, redis.Nil)
+	d.metrics.observeCommand("get", 10*time.Second, errors.New("i/o timeout"))
+
+	var buf bytes.Buffer
+	n, err := NewCollector().Register("primary", d).WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d bytes, wrote %d", n, buf.Len())
+	}
+	out := buf.String()
+
+	for _, line := range []string{
+		"# HELP redisc_up Whether the datasource is connected (1) or not (0).",
+		"# TYPE redisc_up gauge",
+		`redisc_up{datasource="primary"} 0`,
+		"# TYPE redisc_ping_duration_seconds histogram",
+		`redisc_ping_duration_seconds_bucket{datasource="primary",le="0.001"} 0`,
+		`redisc_ping_duration_seconds_bucket{datasource="primary",le="0.0025"} 1`,
+		`redisc_ping_duration_seconds_bucket{datasource="primary",le="+Inf"} 1`,
+		`redisc_ping_duration_seconds_sum{datasource="primary"} 0.002`,
+		`redisc_ping_duration_seconds_count{datasource="primary"} 1`,
+		`redisc_ping_failures_total{datasource="primary"} 1`,
+		"# TYPE redisc_reconnects_total counter",
+		`redisc_reconnects_total{datasource="primary",result="success"} 1`,
+		`redisc_reconnects_total{datasource="primary",result="failure"} 2`,
+		`redisc_commands_total{datasource="primary",command="get"} 3`,
+		`redisc_command_errors_total{datasource="primary",command="get"} 1`,
+		"# TYPE redisc_command_duration_seconds histogram",
+		`redisc_command_duration_seconds_bucket{datasource="primary",command="get",le="0.0005"} 1`,
+		`redisc_command_duration_seconds_bucket{datasource="primary",command="get",le="0.005"} 2`,
+		`redisc_command_duration_seconds_bucket{datasource="primary",command="get",le="2.5"} 2`,
+		`redisc_command_duration_seconds_bucket{datasource="primary",command="get",le="+Inf"} 3`,
+		`redisc_command_duration_seconds_sum{datasource="primary",command="get"} 10.0033`,
+		`redisc_command_duration_seconds_count{datasource="primary",command="get"} 3`,
+		`redisc_events_dropped_total{datasource="primary"} 0`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("WriteTo() output is missing %q\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, `redisc_pool_hits_total{`) {
+		t.Errorf("WriteTo() rendered pool samples for a datasource without connection\n%s", out)
+	}
+}
+
+func TestCollectorNamespace(t *testing.T) {
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"", "# TYPE up gauge\n"},
+		{"app", "# TYPE app_up gauge\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		c := NewCollector().SetNamespace(tt.namespace).Register("a", NewClient(*NewSettings()))
+		if _, err := c.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() error = %v", err)
+		}
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("namespace %q: output is missing %q", tt.namespace, tt.want)
+		}
+	}
+}
+
+func TestFormatLabels(t *testing.T) {
+	tests := []struct {
+		pairs []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"datasource", "primary"}, `{datasource="primary"}`},
+		{[]string{"a", `x"y`, "b", "1\\2\n"}, `{a="x\"y",b="1\\2\n"}`},
+	}
+	for _, tt := range tests {
+		if got := formatLabels(tt.pairs); got != tt.want {
+			t.Errorf("formatLabels(%q) = %s, want %s", tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestInstrumentObservesPipelinedCommands(t *testing.T) {
+	d := NewClient(*NewSettings())
+	// Nothing listens on port 1, so every command fails without waiting.
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: time.Second})
+	defer client.Close()
+	d.instrument(client)
+	client.Pipelined(func(pipe redis.Pipeliner) error {
+		pipe.Get("a")
+		pipe.Get("b")
+		pipe.Set("c", "1", 0)
+		return nil
+	})
+
+	s := d.snapshot("primary")
+	for name, want := range map[string]uint64{"get": 2, "set": 1} {
+		got := s.commands[name]
+		if got.calls != want || got.errors != want || got.latency.count != want {
+			t.Errorf("command %q: calls = %d, errors = %d, observations = %d, want %d", name, got.calls, got.errors, got.latency.count, want)
+		}
+	}
+}
+
+func TestReconnectContextObservesAttempts(t *testing.T) {
+	d := newFakeDatasource(t, newFakeServer(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if response := d.ReconnectContext(ctx); response.StatusCode() != http.StatusRequestTimeout {
+		t.Errorf("ReconnectContext() = %d on a cancelled context, want %d", response.StatusCode(), http.StatusRequestTimeout)
+	}
+	if response := d.Reconnect(); !response.IsSuccess() {
+		t.Fatalf("Reconnect() failed: %v", response.Cause())
+	}
+	s := d.snapshot("primary")
+	if s.reconnects != 1 || s.reconnectFailures != 0 {
+		t.Errorf("reconnects = %d, failures = %d, want 1 and 0", s.reconnects, s.reconnectFailures)
+	}
+}
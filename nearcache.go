@@ -0,0 +1,564 @@
+package redisc
+
+import (
+	"container/heap"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/wrapify"
+)
+
+// NewNearCache creates a NearCache holding up to size entries (unbounded if size is 0), each for up to
+// ttl (until invalidated if ttl is 0), in front of the given Cache, and starts listening to the
+// invalidation messages of the other instances. The entries are evicted with EvictLRU unless changed
+// with SetPolicy.
+//
+// The invalidation messages are broadcast on the "redisc:invalidate:<prefix>" channel, so every instance
+// sharing a prefix must read and write its keys through a NearCache for the in-process entries to stay
+// consistent. While the instance is not subscribed, e.g. during a reconnection, the invalidation messages
+// are missed: the in-process entries are purged once it is subscribed again, and ttl bounds the staleness
+// in the meantime.
+//
+// When tracking is enabled in the Settings of the Datasource (see Settings.IsTracking), the invalidation
+// messages are pushed by the server (CLIENT TRACKING) instead: every write of the keys is then observed,
+// including the writes that do not go through a NearCache, and nothing is broadcast over pub/sub.
+func NewNearCache[T any](cache *Cache[T], size int, ttl time.Duration) *NearCache[T] {
+	n := &NearCache[T]{
+		cache:   cache,
+		local:   newLocalCache[T](size, ttl, EvictLRU),
+		channel: defaultInvalidationChannel + cache.prefix,
+		id:      randomID(),
+		done:    make(chan struct{}),
+		tracked: cache.datasource.conf.IsTracking(),
+	}
+	if n.tracked {
+		n.untrack = cache.datasource.OnInvalidate(n.invalidated)
+	} else {
+		go n.listen()
+	}
+	return n
+}
+
+// Get retrieves the value stored under key, from the in-process entries if possible.
+// It is equivalent to GetContext with a background context.
+func (n *NearCache[T]) Get(key string) (T, wrapify.R) {
+	return n.GetContext(context.Background(), key)
+}
+
+// GetContext retrieves the value stored under key from the in-process entries, or from redis on a miss,
+// in which case the value is kept in-process, giving up as soon as ctx expires or is cancelled.
+//
+// Returns the same results as Cache.GetContext.
+func (n *NearCache[T]) GetContext(ctx context.Context, key string) (T, wrapify.R) {
+	if value, ok := n.local.get(key); ok {
+		return value, nearResponse(key, value)
+	}
+	ticket := n.local.acquire(key)
+	defer n.local.release(ticket)
+	if n.tracked {
+		// Only the values read through the tracked client are invalidated by the server.
+		client := n.cache.datasource.TrackedClient()
+		if client == nil {
+			return n.cache.GetContext(ctx, key)
+		}
+		if _, response, ok := n.cache.client(ctx); !ok {
+			var value T
+			return value, response
+		}
+		value, response := n.cache.get(ctx, n.cache.datasource.bind(ctx, client), key)
+		if response.IsSuccess() {
+			n.local.put(ticket, value)
+		}
+		return value, response
+	}
+	value, response := n.cache.GetContext(ctx, key)
+	if response.IsSuccess() {
+		n.local.put(ticket, value)
+	}
+	return value, response
+}
+
+// GetOrLoad retrieves the value stored under key, loading it with loader on a miss.
+// It is equivalent to GetOrLoadContext with a background context.
+func (n *NearCache[T]) GetOrLoad(key string, ttl time.Duration, loader Loader[T]) (T, wrapify.R) {
+	return n.GetOrLoadContext(context.Background(), key, ttl, loader)
+}
+
+// GetOrLoadContext retrieves the value stored under key from the in-process entries, or with
+// Cache.GetOrLoadContext on a miss, in which case the value is kept in-process. When the invalidation
+// messages are pushed by the server, a loaded value is only kept in-process once read again.
+func (n *NearCache[T]) GetOrLoadContext(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, wrapify.R) {
+	if value, ok := n.local.get(key); ok {
+		return value, nearResponse(key, value)
+	}
+	if n.tracked {
+		value, response := n.GetContext(ctx, key)
+		if response.StatusCode() != http.StatusNotFound {
+			return value, response
+		}
+		return n.cache.GetOrLoadContext(ctx, key, ttl, loader)
+	}
+	ticket := n.local.acquire(key)
+	defer n.local.release(ticket)
+	value, response := n.cache.GetOrLoadContext(ctx, key, ttl, loader)
+	if response.IsSuccess() {
+		n.local.put(ticket, value)
+	}
+	return value, response
+}
+
+// Set stores the value under key, expiring after ttl in redis, tagged with the given tags.
+// It is equivalent to SetContext with a background context.
+func (n *NearCache[T]) Set(key string, value T, ttl time.Duration, tags ...string) wrapify.R {
+	return n.SetContext(context.Background(), key, value, ttl, tags...)
+}
+
+// SetContext stores the value under key in redis, expiring after ttl (never if ttl is zero), then keeps
+// it in-process and broadcasts the invalidation of the key to the other instances, giving up as soon as
+// ctx expires or is cancelled. The key is tagged as with Cache.SetContext. A failure to broadcast the
+// invalidation does not fail the write, see notifyWritten.
+//
+// Returns:
+//   - a 200 response if the value has been stored;
+//   - an error response otherwise.
+func (n *NearCache[T]) SetContext(ctx context.Context, key string, value T, ttl time.Duration, tags ...string) wrapify.R {
+	n.local.invalidate(key)
+	// The value is not kept in-process if another instance writes the key concurrently.
+	ticket := n.local.acquire(key)
+	defer n.local.release(ticket)
+	response := n.cache.SetContext(ctx, key, value, ttl, tags...)
+	if !response.IsSuccess() {
+		return response
+	}
+	if !n.tracked {
+		n.local.put(ticket, value)
+	}
+	n.notifyWritten(ctx, key)
+	return response
+}
+
+// Delete removes the given keys.
+// It is equivalent to DeleteContext with a background context.
+func (n *NearCache[T]) Delete(keys ...string) wrapify.R {
+	return n.DeleteContext(context.Background(), keys...)
+}
+
+// DeleteContext removes the given keys from redis and from the in-process entries, then broadcasts their
+// invalidation to the other instances, giving up as soon as ctx expires or is cancelled.
+//
+// Returns the same results as Cache.DeleteContext. A failure to broadcast the invalidation does not fail
+// the removal, see notifyWritten.
+func (n *NearCache[T]) DeleteContext(ctx context.Context, keys ...string) wrapify.R {
+	n.local.invalidate(keys...)
+	response := n.cache.DeleteContext(ctx, keys...)
+	if !response.IsSuccess() {
+		return response
+	}
+	n.notifyWritten(ctx, keys...)
+	return response
+}
+
+// InvalidateTags removes every key tagged with any of the given tags.
+// It is equivalent to InvalidateTagsContext with a background context.
+func (n *NearCache[T]) InvalidateTags(tags ...string) wrapify.R {
+	return n.InvalidateTagsContext(context.Background(), tags...)
+}
+
+// InvalidateTagsContext removes every key tagged with any of the given tags from redis, as with
+// Cache.InvalidateTagsContext, then evicts them from the in-process entries and broadcasts their
+// invalidation to the other instances, giving up as soon as ctx expires or is cancelled. A failure to
+// broadcast the invalidation does not fail the removal, see notifyWritten.
+func (n *NearCache[T]) InvalidateTagsContext(ctx context.Context, tags ...string) wrapify.R {
+	deleted, response := n.cache.invalidateTags(ctx, tags)
+	if deleted == nil {
+		return response
+	}
+	keys := make([]string, len(deleted))
+	for i, key := range deleted {
+		keys[i] = strings.TrimPrefix(key, n.cache.prefix)
+	}
+	n.local.invalidate(keys...)
+	n.notifyWritten(ctx, keys...)
+	return wrapify.WrapOk("", len(keys)).
+		WithMessagef("Successfully removed %d keys tagged %v", len(keys), tags).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Invalidate evicts the given keys from the in-process entries of every instance, without modifying
+// redis, e.g. after they have been written by other means.
+// It is equivalent to InvalidateContext with a background context.
+func (n *NearCache[T]) Invalidate(keys ...string) wrapify.R {
+	return n.InvalidateContext(context.Background(), keys...)
+}
+
+// InvalidateContext evicts the given keys from the in-process entries of every instance, without
+// modifying redis, giving up as soon as ctx expires or is cancelled. When the invalidation messages are
+// pushed by the server, the keys are only evicted from this instance.
+func (n *NearCache[T]) InvalidateContext(ctx context.Context, keys ...string) wrapify.R {
+	n.local.invalidate(keys...)
+	if failure, ok := n.broadcast(ctx, keys...); !ok {
+		return failure
+	}
+	return wrapify.WrapOk("", nil).
+		WithMessagef("Successfully invalidated %d keys", len(keys)).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Purge evicts every in-process entry of this instance.
+func (n *NearCache[T]) Purge() {
+	n.local.purge()
+}
+
+// Len returns the number of in-process entries, including the expired ones not evicted yet.
+func (n *NearCache[T]) Len() int {
+	n.local.mu.Lock()
+	defer n.local.mu.Unlock()
+	return len(n.local.entries)
+}
+
+// Close stops listening to the invalidation messages and purges the in-process entries. The NearCache
+// must not be used afterwards. It is safe to call Close more than once.
+func (n *NearCache[T]) Close() {
+	n.once.Do(func() {
+		close(n.done)
+		if n.untrack != nil {
+			n.untrack()
+		}
+		n.local.purge()
+	})
+}
+
+// broadcast publishes the invalidation of the given keys to the other instances. Nothing is published
+// when the invalidation messages are pushed by the server.
+//
+// Returns:
+//   - an empty response and true if the message has been published;
+//   - an error response and false otherwise.
+func (n *NearCache[T]) broadcast(ctx context.Context, keys ...string) (wrapify.R, bool) {
+	if len(keys) == 0 || n.tracked {
+		return wrapify.R{}, true
+	}
+	client, response, ok := n.cache.client(ctx)
+	if !ok {
+		return response, false
+	}
+	message, err := json.Marshal(invalidation{ID: n.id, Keys: keys})
+	if err == nil {
+		err = await(ctx, func() error { return client.Publish(n.channel, message).Err() })
+	}
+	if err != nil {
+		return n.cache.fail(ctx, err, "near_cache_broadcast", "", "Failed to broadcast the invalidation of the keys"), false
+	}
+	return wrapify.R{}, true
+}
+
+// notifyWritten broadcasts the invalidation of the given keys, which have been written to redis. As the
+// write has succeeded, a failure is not returned but published as an EventCommandError and logged as a
+// warning: the other instances may then serve the previous values until their in-process entries expire.
+func (n *NearCache[T]) notifyWritten(ctx context.Context, keys ...string) {
+	if failure, ok := n.broadcast(ctx, keys...); !ok {
+		n.cache.datasource.log(levelWarn, "The invalidation of the written keys could not be broadcast", "channel", n.channel, "keys", keys, "error", failure.Cause())
+	}
+}
+
+// listen subscribes to the invalidation channel and applies the messages of the other instances, until
+// the NearCache or its Datasource is closed. It subscribes again whenever the subscription is lost or
+// the Datasource switches to a new client.
+func (n *NearCache[T]) listen() {
+	d := n.cache.datasource
+	for {
+		if client := d.Client(); client != nil && d.IsConnected() {
+			pubsub := client.Subscribe(n.channel)
+			if _, err := pubsub.Receive(); err != nil {
+				d.log(levelWarn, "The near cache could not subscribe to its invalidation channel", "channel", n.channel, "error", err)
+			} else {
+				// The invalidation messages published while not subscribed have been missed.
+				n.local.purge()
+				if !n.consume(client, pubsub) {
+					pubsub.Close()
+					return
+				}
+			}
+			pubsub.Close()
+		}
+		select {
+		case <-n.done:
+			return
+		case <-d.done:
+			return
+		case <-time.After(defaultResubscribeInterval):
+		}
+	}
+}
+
+// consume applies the invalidation messages received on pubsub.
+//
+// Returns:
+//   - true if the subscription has been lost, or the Datasource has switched to a new client;
+//   - false if the NearCache or its Datasource has been closed.
+func (n *NearCache[T]) consume(client redis.UniversalClient, pubsub *redis.PubSub) bool {
+	d := n.cache.datasource
+	ticker := time.NewTicker(defaultResubscribeInterval)
+	defer ticker.Stop()
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-n.done:
+			return false
+		case <-d.done:
+			return false
+		case <-ticker.C:
+			if d.Client() != client {
+				return true
+			}
+		case message, ok := <-messages:
+			if !ok {
+				return true
+			}
+			var payload invalidation
+			if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+				d.log(levelWarn, "The near cache received an invalid invalidation message", "channel", n.channel, "error", err)
+				continue
+			}
+			if payload.ID != n.id {
+				n.local.invalidate(payload.Keys...)
+			}
+		}
+	}
+}
+
+// invalidated evicts the keys invalidated by the server, given as full redis keys, or every entry if keys is nil.
+func (n *NearCache[T]) invalidated(keys []string) {
+	if keys == nil {
+		n.local.purge()
+		return
+	}
+	local := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, n.cache.prefix) {
+			local = append(local, strings.TrimPrefix(key, n.cache.prefix))
+		}
+	}
+	n.local.invalidate(local...)
+}
+
+// nearResponse builds the response of a value served from the in-process entries.
+func nearResponse(key string, value interface{}) wrapify.R {
+	return wrapify.WrapOk("", value).
+		WithMessagef("Successfully retrieved the key '%s'", key).
+		WithDebuggingKV("near", true).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// randomID returns a random identifier of 16 bytes, hex encoded.
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newLocalCache creates an empty localCache.
+func newLocalCache[T any](size int, ttl time.Duration, policy EvictionPolicy) *localCache[T] {
+	return &localCache[T]{
+		size:    size,
+		ttl:     ttl,
+		policy:  policy,
+		entries: make(map[string]*localEntry[T]),
+		queue:   localQueue[T]{policy: policy},
+		reads:   make(map[string]*localRead),
+	}
+}
+
+// get returns the value of key if it is present and not expired.
+func (l *localCache[T]) get(key string) (T, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var zero T
+	entry, ok := l.entries[key]
+	if !ok {
+		return zero, false
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		l.remove(entry)
+		return zero, false
+	}
+	l.clock++
+	entry.hits++
+	entry.used = l.clock
+	heap.Fix(&l.queue, entry.index)
+	l.age()
+	return entry.value, true
+}
+
+// acquire starts a read of key from redis, whose value is then given to put. Every ticket must be
+// released once the read is over.
+func (l *localCache[T]) acquire(key string) localTicket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	read, ok := l.reads[key]
+	if !ok {
+		read = &localRead{}
+		l.reads[key] = read
+	}
+	read.readers++
+	return localTicket{key: key, generation: read.generation, epoch: l.epoch}
+}
+
+// release ends the read of the ticket.
+func (l *localCache[T]) release(ticket localTicket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if read, ok := l.reads[ticket.key]; ok {
+		read.readers--
+		if read.readers <= 0 {
+			delete(l.reads, ticket.key)
+		}
+	}
+}
+
+// put stores the value read with the ticket, evicting the expired entries, or else the head of the queue,
+// if the cache is full, unless the key has been invalidated or the cache purged since the read started.
+func (l *localCache[T]) put(ticket localTicket, value T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	read, ok := l.reads[ticket.key]
+	if !ok || read.generation != ticket.generation || l.epoch != ticket.epoch {
+		return
+	}
+	l.clock++
+	var expiresAt time.Time
+	if l.ttl > 0 {
+		expiresAt = time.Now().Add(l.ttl)
+	}
+	if entry, ok := l.entries[ticket.key]; ok {
+		entry.value, entry.expiresAt, entry.used = value, expiresAt, l.clock
+		heap.Fix(&l.queue, entry.index)
+		return
+	}
+	if l.size > 0 && len(l.entries) >= l.size {
+		l.removeExpired()
+	}
+	if l.size > 0 && len(l.entries) >= l.size {
+		l.remove(l.queue.entries[0])
+	}
+	// A new entry counts as used once, so that under EvictLFU it is not evicted before the entries that
+	// have not been hit since they were stored.
+	entry := &localEntry[T]{key: ticket.key, value: value, expiresAt: expiresAt, hits: 1, used: l.clock}
+	l.entries[ticket.key] = entry
+	heap.Push(&l.queue, entry)
+}
+
+// invalidate removes the given keys, and prevents the values read before from being stored.
+func (l *localCache[T]) invalidate(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if read, ok := l.reads[key]; ok {
+			read.generation++
+		}
+		if entry, ok := l.entries[key]; ok {
+			l.remove(entry)
+		}
+	}
+}
+
+// purge removes every entry.
+func (l *localCache[T]) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.epoch++
+	l.entries = make(map[string]*localEntry[T])
+	l.queue.entries = nil
+}
+
+// setPolicy changes the eviction policy and reorders the entries accordingly.
+func (l *localCache[T]) setPolicy(policy EvictionPolicy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.policy = policy
+	l.queue.policy = policy
+	heap.Init(&l.queue)
+}
+
+// age halves the hit counts of the entries every defaultLFUAgingPeriod hits per entry of capacity, under
+// EvictLFU. It must be called with mu held.
+func (l *localCache[T]) age() {
+	if l.policy != EvictLFU || l.size <= 0 {
+		return
+	}
+	l.hits++
+	if l.hits < l.size*defaultLFUAgingPeriod {
+		return
+	}
+	l.hits = 0
+	for _, entry := range l.queue.entries {
+		entry.hits /= 2
+	}
+	heap.Init(&l.queue)
+}
+
+// remove removes the entry, which must be present. It must be called with mu held.
+func (l *localCache[T]) remove(entry *localEntry[T]) {
+	heap.Remove(&l.queue, entry.index)
+	delete(l.entries, entry.key)
+}
+
+// removeExpired removes every expired entry. The queue is not ordered by expiration, so every entry is
+// checked; put only does so when the cache is full.
+func (l *localCache[T]) removeExpired() {
+	if l.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for _, entry := range l.entries {
+		if now.After(entry.expiresAt) {
+			l.remove(entry)
+		}
+	}
+}
+
+// Len implements heap.Interface.
+func (q localQueue[T]) Len() int {
+	return len(q.entries)
+}
+
+// Less implements heap.Interface, ordering first the entry to evict according to the policy.
+func (q localQueue[T]) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if q.policy == EvictLFU && a.hits != b.hits {
+		return a.hits < b.hits
+	}
+	return a.used < b.used
+}
+
+// Swap implements heap.Interface.
+func (q localQueue[T]) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
+}
+
+// Push implements heap.Interface.
+func (q *localQueue[T]) Push(x interface{}) {
+	entry := x.(*localEntry[T])
+	entry.index = len(q.entries)
+	q.entries = append(q.entries, entry)
+}
+
+// Pop implements heap.Interface.
+func (q *localQueue[T]) Pop() interface{} {
+	last := len(q.entries) - 1
+	entry := q.entries[last]
+	q.entries[last] = nil
+	q.entries = q.entries[:last]
+	return entry
+}
@@ -0,0 +1,185 @@
+package redisc
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// store stores the value of key in l as read from redis without interference.
+func store[T any](l *localCache[T], key string, value T) {
+	ticket := l.acquire(key)
+	defer l.release(ticket)
+	l.put(ticket, value)
+}
+
+func TestLocalCacheEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  EvictionPolicy
+		actions func(l *localCache[int])
+		want    []string
+		evicted []string
+	}{
+		{"lru evicts the least recently used", EvictLRU, func(l *localCache[int]) {
+			store(l, "a", 1)
+			store(l, "b", 2)
+			l.get("a")
+			store(l, "c", 3)
+		}, []string{"a", "c"}, []string{"b"}},
+		{"lfu evicts the least frequently used", EvictLFU, func(l *localCache[int]) {
+			store(l, "a", 1)
+			l.get("a")
+			l.get("a")
+			store(l, "b", 2)
+			l.get("b")
+			store(l, "c", 3)
+		}, []string{"a", "c"}, []string{"b"}},
+		{"lfu keeps a new entry over an unused older one", EvictLFU, func(l *localCache[int]) {
+			store(l, "a", 1)
+			store(l, "b", 2)
+			store(l, "c", 3)
+		}, []string{"b", "c"}, []string{"a"}},
+		{"overwrite keeps a single entry", EvictLRU, func(l *localCache[int]) {
+			store(l, "a", 1)
+			store(l, "a", 2)
+			store(l, "b", 3)
+		}, []string{"a", "b"}, nil},
+	}
+	for _, tt := range tests {
+		l := newLocalCache[int](2, 0, tt.policy)
+		tt.actions(l)
+		for _, key := range tt.want {
+			if _, ok := l.get(key); !ok {
+				t.Errorf("%s: key %q evicted", tt.name, key)
+			}
+		}
+		for _, key := range tt.evicted {
+			if _, ok := l.get(key); ok {
+				t.Errorf("%s: key %q not evicted", tt.name, key)
+			}
+		}
+	}
+}
+
+func TestLocalCacheTTL(t *testing.T) {
+	l := newLocalCache[string](0, 20*time.Millisecond, EvictLRU)
+	store(l, "a", "1")
+	if value, ok := l.get("a"); !ok || value != "1" {
+		t.Fatalf("get() = %q, %v, want the stored value", value, ok)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := l.get("a"); ok {
+		t.Error("get() returned an expired entry")
+	}
+	if n := len(l.entries); n != 0 {
+		t.Errorf("%d entries retained after expiration", n)
+	}
+}
+
+func TestLocalCacheEvictsExpiredFirst(t *testing.T) {
+	l := newLocalCache[int](2, 20*time.Millisecond, EvictLFU)
+	store(l, "a", 1)
+	l.get("a")
+	l.get("a")
+	time.Sleep(30 * time.Millisecond)
+	store(l, "b", 2)
+	store(l, "c", 3)
+	if _, ok := l.entries["a"]; ok {
+		t.Error("the expired entry was kept")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := l.get(key); !ok {
+			t.Errorf("key %q evicted instead of the expired entry", key)
+		}
+	}
+}
+
+func TestLocalCacheConcurrentInvalidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(l *localCache[int])
+		want       map[string]bool
+	}{
+		{"no invalidation", func(l *localCache[int]) {}, map[string]bool{"a": true, "b": true}},
+		{"key invalidated", func(l *localCache[int]) { l.invalidate("a") }, map[string]bool{"a": false, "b": true}},
+		{"other key invalidated", func(l *localCache[int]) { l.invalidate("c") }, map[string]bool{"a": true, "b": true}},
+		{"purged", func(l *localCache[int]) { l.purge() }, map[string]bool{"a": false, "b": false}},
+	}
+	for _, tt := range tests {
+		l := newLocalCache[int](0, 0, EvictLRU)
+		a, b := l.acquire("a"), l.acquire("b")
+		tt.invalidate(l)
+		l.put(a, 1)
+		l.put(b, 2)
+		l.release(a)
+		l.release(b)
+		for key, want := range tt.want {
+			if _, ok := l.get(key); ok != want {
+				t.Errorf("%s: key %q stored = %v, want %v", tt.name, key, ok, want)
+			}
+		}
+		if n := len(l.reads); n != 0 {
+			t.Errorf("%s: %d reads retained after release", tt.name, n)
+		}
+	}
+}
+
+func TestLocalCacheReadAfterInvalidation(t *testing.T) {
+	l := newLocalCache[int](0, 0, EvictLRU)
+	stale := l.acquire("a")
+	l.invalidate("a")
+	fresh := l.acquire("a")
+	l.put(stale, 1)
+	if _, ok := l.get("a"); ok {
+		t.Fatal("value read before the invalidation stored")
+	}
+	l.put(fresh, 2)
+	if value, ok := l.get("a"); !ok || value != 2 {
+		t.Errorf("get() = %d, %v, want the value read after the invalidation", value, ok)
+	}
+	l.release(stale)
+	l.release(fresh)
+}
+
+func TestLocalCacheLFUAging(t *testing.T) {
+	l := newLocalCache[int](2, 0, EvictLFU)
+	store(l, "a", 1)
+	store(l, "b", 2)
+	for i := 1; i < 2*defaultLFUAgingPeriod; i++ {
+		l.get("a")
+	}
+	if hits := l.entries["a"].hits; hits != 2*defaultLFUAgingPeriod {
+		t.Fatalf("hits = %d before aging, want %d", hits, 2*defaultLFUAgingPeriod)
+	}
+	l.get("a")
+	if hits := l.entries["a"].hits; hits != (2*defaultLFUAgingPeriod+1)/2 {
+		t.Errorf("hits = %d after aging, want %d", hits, (2*defaultLFUAgingPeriod+1)/2)
+	}
+	if hits := l.entries["b"].hits; hits != 0 {
+		t.Errorf("hits = %d after aging, want 0", hits)
+	}
+}
+
+func TestNearCacheWriteSucceedsWithoutBroadcast(t *testing.T) {
+	s := newFakeServer(t)
+	n := NewNearCache(NewCache[string](newFakeDatasource(t, s), nil), 10, time.Minute)
+	defer n.Close()
+	s.Fail("PUBLISH", "ERR publish failed")
+
+	if response := n.Set("a", "1", 0); response.StatusCode() != http.StatusOK {
+		t.Fatalf("Set() = %d, want 200 when only the broadcast fails: %v", response.StatusCode(), response.Cause())
+	}
+	if value, ok := n.local.get("a"); !ok || value != "1" {
+		t.Errorf("in-process value = %q, %v, want the value written", value, ok)
+	}
+	if response := n.Delete("a"); !response.IsSuccess() {
+		t.Errorf("Delete() = %d, want a success when only the broadcast fails", response.StatusCode())
+	}
+	if s.Count("PUBLISH") != 2 {
+		t.Errorf("PUBLISH sent %d times, want 2", s.Count("PUBLISH"))
+	}
+	if response := n.Invalidate("a"); response.IsSuccess() {
+		t.Error("Invalidate() succeeded although its broadcast failed")
+	}
+}
@@ -0,0 +1,206 @@
+package redisc
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sivaosorg/unify4g"
+)
+
+// defaultPort is the port assumed when a redis URL does not specify one.
+const defaultPort = "6379"
+
+// queryOption binds a URL query parameter to a field of Settings, so that the same table
+// drives both ParseSettings and Settings.String.
+type queryOption struct {
+	name string
+	// set reports whether the sub-settings holding the field are set, as the field cannot be read otherwise.
+	set    func(c *Settings) bool
+	format func(c *Settings) string
+	parse  func(c *Settings, value string) error
+}
+
+// queryOptions lists the supported URL query parameters. Settings.String renders them in
+// alphabetical order and only when they differ from the defaults of NewSettings.
+var queryOptions = []queryOption{
+	durationOption("dial_timeout", hasTimeout, func(c *Settings) *time.Duration { return &c.timeout.connTimeout }),
+	durationOption("idle_check_frequency", hasPool, func(c *Settings) *time.Duration { return &c.pool.idleCheckFrequency }),
+	durationOption("idle_timeout", hasPool, func(c *Settings) *time.Duration { return &c.pool.idleTimeout }),
+	durationOption("max_conn_age", hasPool, func(c *Settings) *time.Duration { return &c.pool.maxConnAge }),
+	intOption("max_retries", hasRetry, func(c *Settings) *int { return &c.retry.maxRetries }),
+	durationOption("max_retry_backoff", hasRetry, func(c *Settings) *time.Duration { return &c.retry.maxRetryBackoff }),
+	intOption("min_idle_conns", hasPool, func(c *Settings) *int { return &c.pool.minIdleConn }),
+	durationOption("min_retry_backoff", hasRetry, func(c *Settings) *time.Duration { return &c.retry.minRetryBackoff }),
+	intOption("pool_size", hasPool, func(c *Settings) *int { return &c.pool.poolSize }),
+	durationOption("pool_timeout", hasPool, func(c *Settings) *time.Duration { return &c.pool.poolTimeout }),
+	durationOption("read_timeout", hasTimeout, func(c *Settings) *time.Duration { return &c.timeout.readTimeout }),
+	durationOption("write_timeout", hasTimeout, func(c *Settings) *time.Duration { return &c.timeout.writeTimeout }),
+}
+
+// queryDefaults holds the query parameters rendered for the defaults of NewSettings, by name, which
+// Settings.String leaves out.
+var queryDefaults = formatQueryOptions(NewSettings())
+
+// ParseSettings creates Settings from a redis connection URL. The supported forms are:
+//
+//	redis://<username>:<password>@<host>:<port>/<db>?<options>
+//	rediss://<username>:<password>@<host>:<port>/<db>?<options> (TLS enabled)
+//	unix://<username>:<password>@</path/to/socket>?db=<db>&<options>
+//
+// The options are the query parameters dial_timeout, read_timeout, write_timeout, max_retries,
+// min_retry_backoff, max_retry_backoff, pool_size, min_idle_conns, max_conn_age, pool_timeout,
+// idle_timeout and idle_check_frequency. Durations accept Go duration strings (e.g. "3s") or a plain
+// number of seconds. Fields that are not present in the URL keep the defaults of NewSettings, and the
+// returned Settings are enabled.
+//
+// A URL in canonical form (explicit port, no "/0" database, options in alphabetical order and only
+// when different from the defaults) is reproduced exactly by String(false).
+//
+// Returns:
+//   - the parsed Settings;
+//   - an error if the scheme, host, database or any query parameter is invalid.
+func ParseSettings(rawURL string) (*Settings, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %v", err)
+	}
+	s := NewSettings().SetEnable(true)
+	if u.User != nil {
+		s.conn.username = u.User.Username()
+		s.conn.password, _ = u.User.Password()
+	}
+	query := u.Query()
+	switch u.Scheme {
+	case "redis", "rediss":
+		s.tls.enabled = u.Scheme == "rediss"
+		host, port := u.Hostname(), u.Port()
+		if unify4g.IsEmpty(host) {
+			return nil, fmt.Errorf("invalid redis URL: the host is required")
+		}
+		if unify4g.IsEmpty(port) {
+			port = defaultPort
+		}
+		s.conn.connectionStrings = net.JoinHostPort(host, port)
+		if db := strings.Trim(u.Path, "/"); unify4g.IsNotEmpty(db) {
+			if s.conn.database, err = strconv.Atoi(db); err != nil {
+				return nil, fmt.Errorf("invalid redis URL: the database '%s' is not a number", db)
+			}
+		}
+	case "unix":
+		if unify4g.IsEmpty(u.Path) {
+			return nil, fmt.Errorf("invalid redis URL: the unix socket path is required")
+		}
+		s.conn.network = "unix"
+		s.conn.connectionStrings = u.Path
+	default:
+		return nil, fmt.Errorf("invalid redis URL: unsupported scheme '%s' (expected redis, rediss or unix)", u.Scheme)
+	}
+	if query.Has("db") {
+		db := query.Get("db")
+		if s.conn.database, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis URL: the query parameter 'db' is not a number: '%s'", db)
+		}
+		query.Del("db")
+	}
+	for _, option := range queryOptions {
+		if !query.Has(option.name) {
+			continue
+		}
+		if err := option.parse(s, query.Get(option.name)); err != nil {
+			return nil, fmt.Errorf("invalid redis URL: the query parameter '%s' %v", option.name, err)
+		}
+		query.Del(option.name)
+	}
+	for name := range query {
+		return nil, fmt.Errorf("invalid redis URL: unknown query parameter '%s'", name)
+	}
+	return s, nil
+}
+
+// query renders the query parameters of the connection URL, keeping only the options
+// that differ from the defaults of NewSettings.
+func (c *Settings) query() url.Values {
+	values := url.Values{}
+	if c.conn.network == "unix" && c.conn.database != 0 {
+		values.Set("db", strconv.Itoa(c.conn.database))
+	}
+	for _, option := range queryOptions {
+		if !option.set(c) {
+			continue
+		}
+		if value := option.format(c); value != queryDefaults[option.name] {
+			values.Set(option.name, value)
+		}
+	}
+	return values
+}
+
+// formatQueryOptions renders the query parameters of the Settings, by name, leaving out those whose
+// sub-settings are not set.
+func formatQueryOptions(c *Settings) map[string]string {
+	values := make(map[string]string, len(queryOptions))
+	for _, option := range queryOptions {
+		if option.set(c) {
+			values[option.name] = option.format(c)
+		}
+	}
+	return values
+}
+
+// durationOption binds a duration query parameter to the Settings field returned by field.
+func durationOption(name string, set func(c *Settings) bool, field func(c *Settings) *time.Duration) queryOption {
+	return queryOption{
+		name: name,
+		set:  set,
+		format: func(c *Settings) string {
+			return formatDuration(*field(c))
+		},
+		parse: durationField(field),
+	}
+}
+
+// intOption binds an integer query parameter to the Settings field returned by field.
+func intOption(name string, set func(c *Settings) bool, field func(c *Settings) *int) queryOption {
+	return queryOption{
+		name: name,
+		set:  set,
+		format: func(c *Settings) string {
+			return strconv.Itoa(*field(c))
+		},
+		parse: intField(field),
+	}
+}
+
+// hasRetry, hasTimeout and hasPool report whether the retry, timeout and pool settings are set.
+func hasRetry(c *Settings) bool   { return c.retry != nil }
+func hasTimeout(c *Settings) bool { return c.timeout != nil }
+func hasPool(c *Settings) bool    { return c.pool != nil }
+
+// parseDuration parses a Go duration string (e.g. "250ms", "3s") or a plain number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	if n, err := strconv.Atoi(value); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("is not a valid duration: '%s'", value)
+	}
+	return d, nil
+}
+
+// formatDuration renders a duration in its shortest Go duration form, dropping the
+// zero-valued trailing units that time.Duration.String keeps (e.g. "5m" instead of "5m0s").
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
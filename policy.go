@@ -0,0 +1,144 @@
+package redisc
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// delay computes the wait before the next reconnection attempt, given the number of consecutive
+// failed attempts so far: initialDelay * multiplier^(failures-1), capped at maxDelay, then spread
+// by ±jitter.
+func (r *reconnectSettings) delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	d := float64(r.initialDelay) * math.Pow(math.Max(r.multiplier, 1), float64(failures-1))
+	if r.maxDelay > 0 && d > float64(r.maxDelay) {
+		d = float64(r.maxDelay)
+	}
+	if r.jitter > 0 {
+		d += d * r.jitter * (2*rand.Float64() - 1)
+	}
+	if d < 0 {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+// reconnectWithPolicy repeatedly attempts to reconnect following the reconnect policy, independently of the
+// ping interval, until a reconnection succeeds, the policy gives up or the Datasource is closed.
+// The outcome of every attempt is published; when the policy gives up, a distinct terminal response
+// is recorded and published as an EventReconnectGaveUp event, then the configured give-up action is applied.
+//
+// Returns:
+//   - true if the keepalive routine should keep running;
+//   - false if it should stop, because the Datasource is closed or the give-up action requires it.
+func (d *Datasource) reconnectWithPolicy() bool {
+	policy := d.conf.reconnect
+	if policy == nil {
+		policy = NewReconnectSettings()
+	}
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(policy.delay(attempt - 1))
+			select {
+			case <-d.done:
+				timer.Stop()
+				return false
+			case <-timer.C:
+			}
+		}
+		ps := time.Now()
+		err := d.reconnect()
+		duration := time.Since(ps)
+		if errors.Is(err, errDatasourceClosed) {
+			return false
+		}
+		d.metrics.observeReconnect(err)
+		if err == nil {
+			d.setAttempts(0)
+			d.publish(EventReconnected, StateConnected, wrapify.New().
+				WithStatusCode(http.StatusOK).
+				WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+				WithDebuggingKV("reconnect_executed_in", duration.String()).
+				WithDebuggingKV("reconnect_start_at", ps.Format(defaultTimeFormat)).
+				WithDebuggingKV("reconnect_end_at", ps.Add(duration).Format(defaultTimeFormat)).
+				WithDebuggingKV("reconnect_attempt", attempt).
+				WithMessagef("The connection to the redis server has been successfully re-established: '%s'", d.conf.String(true)).
+				WithHeader(wrapify.OK).
+				Reply())
+			return true
+		}
+		d.setAttempts(attempt)
+		d.publish(EventReconnectFailed, StateReconnecting, wrapify.WrapInternalServerError("The redis server remains unreachable. The reconnection attempt has failed", nil).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithDebuggingKV("reconnect_executed_in", duration.String()).
+			WithDebuggingKV("reconnect_start_at", ps.Format(defaultTimeFormat)).
+			WithDebuggingKV("reconnect_end_at", ps.Add(duration).Format(defaultTimeFormat)).
+			WithDebuggingKV("reconnect_attempt", attempt).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError).
+			Reply())
+		if policy.maxAttempts > 0 && attempt >= policy.maxAttempts {
+			return d.giveUp(policy, attempt, err)
+		}
+	}
+}
+
+// giveUp records and publishes the terminal response of a reconnection cycle that exhausted its
+// attempts, then applies the give-up action of the policy.
+//
+// Returns:
+//   - true if the keepalive routine should keep running (GiveUpResume);
+//   - false otherwise.
+func (d *Datasource) giveUp(policy *reconnectSettings, attempts int, err error) bool {
+	if d.IsClosed() {
+		return false
+	}
+	response := wrapify.WrapServiceUnavailable("", nil).
+		WithMessagef("The reconnection policy gave up after %d attempts", attempts).
+		WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+		WithDebuggingKV("event", "reconnect_give_up").
+		WithDebuggingKV("reconnect_attempt", attempts).
+		WithDebuggingKV("reconnect_give_up", string(policy.giveUp)).
+		WithErrSck(err).
+		WithHeader(wrapify.ServiceUnavailable).
+		Reply()
+	changed := d.transition(StateDisconnected, response)
+	d.SetWrap(response)
+	d.logResponse(levelOf(EventReconnectGaveUp), response)
+	d.events.publish(Event{Type: EventReconnectGaveUp, State: StateDisconnected, Changed: changed, Response: response, At: time.Now()})
+	switch policy.giveUp {
+	case GiveUpStop:
+		d.setStopped(true)
+		return false
+	case GiveUpClose:
+		// Close waits for the keepalive routine, which is the caller, so it must run separately.
+		go d.Close()
+		return false
+	default:
+		return true
+	}
+}
+
+// resume clears the stop of the keepalive routine by a GiveUpStop reconnection policy, once a manual
+// reconnection succeeded.
+//
+// Returns:
+//   - true if the keepalive routine must be started again, i.e. it was stopped, keepalive is enabled
+//     and the Datasource is not closed;
+//   - false otherwise.
+func (d *Datasource) resume() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.stopped || d.closed {
+		return false
+	}
+	d.stopped = false
+	return d.conf.keepalive
+}
@@ -0,0 +1,80 @@
+package redisc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *reconnectSettings
+		failures int
+		want     time.Duration
+	}{
+		{"first attempt", NewReconnectSettings().SetJitter(0), 0, 0},
+		{"initial delay", NewReconnectSettings().SetJitter(0), 1, time.Second},
+		{"exponential", NewReconnectSettings().SetJitter(0), 3, 4 * time.Second},
+		{"capped", NewReconnectSettings().SetJitter(0), 10, 30 * time.Second},
+		{"uncapped", NewReconnectSettings().SetJitter(0).SetMaxDelay(0), 10, 512 * time.Second},
+		{"constant", NewReconnectSettings().SetJitter(0).SetMultiplier(1), 5, time.Second},
+		{"multiplier below 1", NewReconnectSettings().SetJitter(0).SetMultiplier(0.5), 5, time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.delay(tt.failures); got != tt.want {
+			t.Errorf("%s: delay(%d) = %v, want %v", tt.name, tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestReconnectDelayJitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		jitter   float64
+		failures int
+		min, max time.Duration
+	}{
+		{"initial delay", 0.2, 1, 800 * time.Millisecond, 1200 * time.Millisecond},
+		{"capped", 0.2, 10, 24 * time.Second, 36 * time.Second},
+		{"full", 1, 2, 0, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		policy := NewReconnectSettings().SetJitter(tt.jitter)
+		spread := make(map[time.Duration]bool)
+		for i := 0; i < 200; i++ {
+			got := policy.delay(tt.failures)
+			if got < tt.min || got > tt.max {
+				t.Fatalf("%s: delay(%d) = %v, want within [%v, %v]", tt.name, tt.failures, got, tt.min, tt.max)
+			}
+			spread[got] = true
+		}
+		if len(spread) < 2 {
+			t.Errorf("%s: delay(%d) is not spread by the jitter", tt.name, tt.failures)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopped   bool
+		keepalive bool
+		closed    bool
+		want      bool
+	}{
+		{"running", false, true, false, false},
+		{"stopped", true, true, false, true},
+		{"stopped without keepalive", true, false, false, false},
+		{"stopped then closed", true, true, true, false},
+	}
+	for _, tt := range tests {
+		d := NewClient(*NewSettings().SetKeepalive(tt.keepalive))
+		d.stopped, d.closed = tt.stopped, tt.closed
+		if got := d.resume(); got != tt.want {
+			t.Errorf("%s: resume() = %v, want %v", tt.name, got, tt.want)
+		}
+		if tt.stopped && !tt.closed && d.stopped {
+			t.Errorf("%s: the stop is not cleared", tt.name)
+		}
+	}
+}
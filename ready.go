@@ -0,0 +1,101 @@
+package redisc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// WaitReady blocks until the Datasource is ready to serve commands, which is mostly useful in lazy mode
+// (see Settings.SetLazy), where NewClient returns before the connection is established.
+//
+// Returns:
+//   - nil once the first connection has succeeded;
+//   - the error of the last connection attempt if the Datasource stopped trying to connect, e.g. because
+//     it is not enabled or the reconnection policy gave up with GiveUpStop;
+//   - errDatasourceClosed if the Datasource is closed before being ready;
+//   - the context error if ctx expires or is cancelled first.
+func (d *Datasource) WaitReady(ctx context.Context) error {
+	if d.IsClosed() {
+		return errDatasourceClosed
+	}
+	if d.ready == nil {
+		if d.IsConnected() {
+			return nil
+		}
+		return errConnUnavailable
+	}
+	select {
+	case <-d.ready:
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return d.readyErr
+	case <-d.done:
+		return errDatasourceClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connect starts the background routine that establishes the initial connection in lazy mode. Attempts
+// follow the reconnect policy; when the policy gives up with GiveUpResume, a new cycle starts after the
+// ping interval. Once connected, the keepalive routine takes over if keepalive is enabled.
+func (d *Datasource) connect() {
+	interval := d.conf.PingInterval()
+	if interval <= 0 {
+		interval = defaultPingInterval
+	}
+	d.routines.Add(1)
+	go func() {
+		defer d.routines.Done()
+		for {
+			if !d.reconnectWithPolicy() {
+				// The Datasource is closed, or the policy gave up for good.
+				d.signalReady(d.Wrap())
+				return
+			}
+			if d.State() == StateConnected {
+				break
+			}
+			timer := time.NewTimer(interval)
+			select {
+			case <-d.done:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if d.conf.keepalive {
+			d.keepalive()
+		}
+	}()
+}
+
+// signalReady marks the Datasource as ready, reporting the error of the given response to WaitReady
+// unless it is successful. Only the first call has an effect.
+func (d *Datasource) signalReady(response wrapify.R) {
+	var err error
+	if !response.IsSuccess() {
+		if err = response.Cause(); err == nil {
+			err = errors.New(response.Message())
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.signalReadyLocked(err)
+}
+
+// signalReadyLocked is the lock-free part of signalReady; the caller must hold d.mu for writing.
+func (d *Datasource) signalReadyLocked(err error) {
+	if d.ready == nil {
+		return
+	}
+	select {
+	case <-d.ready:
+	default:
+		d.readyErr = err
+		close(d.ready)
+	}
+}
@@ -1,99 +1,296 @@
 package redisc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
-	"github.com/sivaosorg/loggy"
 	"github.com/sivaosorg/wrapify"
 )
 
 func NewClient(conf Settings) *Datasource {
+	return NewClientContext(context.Background(), conf)
+}
+
+// NewClientContext creates a Datasource like NewClient, bounding the initial connection by ctx.
+// If ctx expires or is cancelled before the redis server answers, the returned Datasource reports
+// the context error in its wrap response. In lazy mode (see Settings.SetLazy), ctx is not used and
+// the Datasource is returned immediately, while the connection is established in the background.
+func NewClientContext(ctx context.Context, conf Settings) *Datasource {
 	datasource := &Datasource{
-		conf: conf,
+		conf:  conf,
+		done:  make(chan struct{}),
+		ready: make(chan struct{}),
 	}
+	datasource.open(ctx)
+	// Without lazy mode, a Datasource that failed to connect does not retry on its own.
+	if datasource.State() == StateDisconnected {
+		datasource.signalReady(datasource.Wrap())
+	}
+	return datasource
+}
+
+// open establishes the initial connection described by the Datasource configuration, records the
+// outcome in the wrap response and, if keepalive is enabled and the connection succeeded, starts the
+// background routine that monitors the connection health. The initial connection is bounded by ctx.
+// In lazy mode, open only starts the background routine that establishes the connection.
+func (d *Datasource) open(ctx context.Context) {
 	start := time.Now()
-	if !conf.IsEnabled() {
-		datasource.SetWrap(wrapify.
+	if !d.conf.IsEnabled() {
+		d.settle(levelDebug, StateDisconnected, wrapify.
 			WrapServiceUnavailable("Redis service unavailable", nil).
 			WithDebuggingKV("executed_in", time.Since(start).String()).
 			WithHeader(wrapify.ServiceUnavailable).
 			Reply())
-		return datasource
+		return
 	}
-	ops := datasource.getOptions()
-	c := redis.NewClient(ops)
-
-	// Use a context with timeout to verify the connection via ping.
-	err := c.Ping().Err()
-	if err != nil {
-		datasource.SetWrap(
+	// Reject an invalid configuration upfront, reporting every problem at once.
+	if err := d.conf.Validate(); err != nil {
+		d.settle(levelError, StateDisconnected,
+			wrapify.
+				WrapBadRequest("The redis configuration is invalid", nil).
+				WithDebuggingKV("executed_in", time.Since(start).String()).
+				WithErrSck(err).
+				WithHeader(wrapify.BadRequest).
+				Reply(),
+		)
+		return
+	}
+	// In lazy mode, the initial connection is established in the background under the reconnect policy.
+	if d.conf.IsLazy() {
+		d.settle(levelInfo, StateConnecting, wrapify.
+			WrapServiceUnavailable("", nil).
+			WithMessagef("The connection to the redis server is being established: '%s'", d.conf.String(true)).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithHeader(wrapify.ServiceUnavailable).
+			Reply())
+		d.connect()
+		return
+	}
+	// Establish the initial connection (single-node, sentinel or cluster) and verify it via ping.
+	if err := d.reconnectContext(ctx); err != nil {
+		d.settle(levelError, StateDisconnected,
 			wrapify.
 				WrapInternalServerError("The redis server is unreachable", nil).
-				WithDebuggingKV("redis_conn_str", conf.String(true)).
+				WithDebuggingKV("redis_conn_str", d.conf.String(true)).
 				WithDebuggingKV("executed_in", time.Since(start).String()).
 				WithErrSck(err).
 				WithHeader(wrapify.InternalServerError).
 				Reply(),
 		)
-		return datasource
+		return
 	}
 
-	// Set the established connection and update the wrap response to indicate success.
-	datasource.SetConn(c)
-	datasource.SetWrap(wrapify.New().
+	// Update the wrap response to indicate success.
+	d.settle(levelInfo, StateConnected, wrapify.New().
 		WithStatusCode(http.StatusOK).
-		WithDebuggingKV("redis_conn_str", conf.String(true)).
+		WithDebuggingKV("redis_conn_str", d.conf.String(true)).
 		WithDebuggingKV("executed_in", time.Since(start).String()).
-		WithMessagef("Successfully connected to the redis server: '%s'", conf.String(true)).
+		WithMessagef("Successfully connected to the redis server: '%s'", d.conf.String(true)).
 		WithHeader(wrapify.OK).
 		Reply())
 
 	// If keepalive is enabled, initiate the background routine to monitor connection health.
-	if conf.keepalive {
-		datasource.keepalive()
+	if d.conf.keepalive {
+		d.keepalive()
 	}
-	return datasource
 }
 
+// AllKeys retrieves every key of the keyspace along with its type.
+// It is equivalent to AllKeysContext with a background context.
 func (d *Datasource) AllKeys() wrapify.R {
+	return d.AllKeysContext(context.Background())
+}
+
+// AllKeysContext retrieves every key of the keyspace along with its type, scanning every master
+// node in cluster mode. The scan is aborted as soon as ctx expires or is cancelled, in which case
+// a request timeout response carrying the context error is returned.
+func (d *Datasource) AllKeysContext(ctx context.Context) wrapify.R {
+	if d.IsClosed() {
+		return closedResponse()
+	}
 	if !d.IsConnected() {
 		return d.Wrap()
 	}
 	keys := make(map[string]string)
+	if cluster := d.ClusterConn(); cluster != nil {
+		// Each master owns a distinct subset of the hash slots, so the keyspace is the union
+		// of the keys scanned on every master node.
+		var mu sync.Mutex
+		var failure *wrapify.R
+		err := cluster.ForEachMaster(func(client *redis.Client) error {
+			nodeKeys := make(map[string]string)
+			response, ok := d.scanKeys(ctx, client, nodeKeys)
+			mu.Lock()
+			defer mu.Unlock()
+			if !ok {
+				failure = &response
+				return fmt.Errorf("failed to retrieve keys from node '%s'", client.Options().Addr)
+			}
+			for key, keyType := range nodeKeys {
+				keys[key] = keyType
+			}
+			return nil
+		})
+		if failure != nil {
+			return *failure
+		}
+		if err != nil {
+			response := wrapify.
+				WrapInternalServerError("A technical issue arose during the retrieval of all keys", nil).
+				WithHeader(wrapify.InternalServerError).
+				WithDebuggingKV("function", "all_keys").
+				WithErrSck(err).Reply()
+			d.notify(EventCommandError, response)
+			return response
+		}
+	} else if response, ok := d.scanKeys(ctx, d.Conn(), keys); !ok {
+		return response
+	}
+	return wrapify.WrapOk("Successfully retrieved all keys", keys).WithTotal(len(keys)).WithHeader(wrapify.OK).Reply()
+}
+
+// Ping checks the health of the current connection.
+// It is equivalent to PingContext with a background context.
+func (d *Datasource) Ping() wrapify.R {
+	return d.PingContext(context.Background())
+}
+
+// PingContext checks the health of the current connection, pinging every master node in cluster mode,
+// and records the round-trip time on success. It does not change the connection status reported by Wrap.
+// The ping is abandoned as soon as ctx expires or is cancelled, in which case a request timeout
+// response carrying the context error is returned.
+func (d *Datasource) PingContext(ctx context.Context) wrapify.R {
+	if d.IsClosed() {
+		return closedResponse()
+	}
+	ps := time.Now()
+	err := d.pingContext(ctx)
+	duration := time.Since(ps)
+	if ctx.Err() != nil {
+		return cancelledResponse(ctx.Err(), "The ping to the redis server has been cancelled")
+	}
+	if err != nil {
+		return wrapify.WrapInternalServerError("The redis server is currently unreachable", nil).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithDebuggingKV("ping_executed_in", duration.String()).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError).
+			Reply()
+	}
+	d.setLatency(duration)
+	return wrapify.New().
+		WithStatusCode(http.StatusOK).
+		WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+		WithDebuggingKV("ping_executed_in", duration.String()).
+		WithMessagef("The connection to the redis server is healthy: '%s'", d.conf.String(true)).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// Reconnect replaces the current connection with a new one.
+// It is equivalent to ReconnectContext with a background context.
+func (d *Datasource) Reconnect() wrapify.R {
+	return d.ReconnectContext(context.Background())
+}
+
+// ReconnectContext replaces the current connection with a new one verified via ping, then records the
+// outcome as the connection status and propagates it to the registered callbacks. The attempt is
+// abandoned as soon as ctx expires or is cancelled, in which case the previous connection is kept and
+// a request timeout response carrying the context error is returned. If the reconnection policy gave up
+// with GiveUpStop, a successful reconnection starts the keepalive routine again.
+func (d *Datasource) ReconnectContext(ctx context.Context) wrapify.R {
+	if d.IsClosed() {
+		return closedResponse()
+	}
+	ps := time.Now()
+	err := d.reconnectContext(ctx)
+	duration := time.Since(ps)
+	// An attempt abandoned because the Datasource was closed or ctx ended is neither a reconnection
+	// nor a failure of the server.
+	if errors.Is(err, errDatasourceClosed) {
+		return closedResponse()
+	}
+	if ctx.Err() != nil {
+		return cancelledResponse(ctx.Err(), "The reconnection to the redis server has been cancelled")
+	}
+	d.metrics.observeReconnect(err)
+	var response wrapify.R
+	if err != nil {
+		response = wrapify.WrapInternalServerError("The redis server remains unreachable. The reconnection attempt has failed", nil).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithDebuggingKV("reconnect_executed_in", duration.String()).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError).
+			Reply()
+	} else {
+		response = wrapify.New().
+			WithStatusCode(http.StatusOK).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithDebuggingKV("reconnect_executed_in", duration.String()).
+			WithMessagef("The connection to the redis server has been successfully re-established: '%s'", d.conf.String(true)).
+			WithHeader(wrapify.OK).
+			Reply()
+	}
+	d.publish(eventOf(response), stateOf(response, StateDisconnected), response)
+	if err == nil && d.resume() {
+		d.keepalive()
+	}
+	return response
+}
+
+// scanKeys iterates over the keyspace of a single redis node using SCAN and records
+// the type of every key found into keys.
+//
+// Returns:
+//   - an empty response and true if the whole keyspace was scanned;
+//   - the response describing the failure and false otherwise.
+func (d *Datasource) scanKeys(ctx context.Context, client *redis.Client, keys map[string]string) (wrapify.R, bool) {
+	bound := d.bind(ctx, client)
 	var cursor uint64
 	for {
 		var batchKeys []string
-		var err error
-		batchKeys, cursor, err = d.Conn().Scan(cursor, "*", 10).Result()
+		var next uint64
+		err := await(ctx, func() error {
+			var err error
+			batchKeys, next, err = bound.Scan(cursor, "*", 10).Result()
+			return err
+		})
+		if ctx.Err() != nil {
+			response := cancelledResponse(ctx.Err(), "The retrieval of all keys has been cancelled")
+			d.notify(EventCommandError, response)
+			return response, false
+		}
+		cursor = next
 		if err != nil {
-			if d.conf.IsDebugging() {
-				loggy.Errorf("A technical issue arose during the retrieval of all keys: %s", err.Error())
-			}
 			response := wrapify.
 				WrapInternalServerError("A technical issue arose during the retrieval of all keys", nil).
 				WithHeader(wrapify.InternalServerError).
 				WithDebuggingKV("function", "all_keys").
 				WithErrSck(err).Reply()
-			d.notify(response)
-			return response
+			d.notify(EventCommandError, response)
+			return response, false
 		}
 		for _, key := range batchKeys {
-			keyType, err := d.Conn().Type(key).Result()
+			if ctx.Err() != nil {
+				response := cancelledResponse(ctx.Err(), "The retrieval of all keys has been cancelled")
+				d.notify(EventCommandError, response)
+				return response, false
+			}
+			keyType, err := bound.Type(key).Result()
 			if err != nil {
-				if d.conf.IsDebugging() {
-					loggy.Errorf("Failed to determine the type of key '%s': %s", key, err.Error())
-				}
 				response := wrapify.
 					WrapInternalServerError("", nil).
 					WithMessagef("Failed to determine the type of key '%s'", key).
 					WithHeader(wrapify.InternalServerError).
 					WithDebuggingKV("function", "all_keys").
 					WithErrSck(err).Reply()
-				d.notify(response)
-				return response
+				d.notify(EventCommandError, response)
+				return response, false
 			}
 			keys[key] = keyType
 		}
@@ -101,10 +298,20 @@ func (d *Datasource) AllKeys() wrapify.R {
 			break
 		}
 	}
-	return wrapify.WrapOk("Successfully retrieved all keys", keys).WithTotal(len(keys)).WithHeader(wrapify.OK).Reply()
+	return wrapify.R{}, true
 }
 
-func (d *Datasource) getOptions() *redis.Options {
+// getOptions builds the redis.Options from the current configuration, including the TLS
+// configuration when TLS is enabled.
+//
+// Returns:
+//   - the options ready to be used by redis.NewClient;
+//   - an error if the TLS configuration cannot be loaded.
+func (d *Datasource) getOptions() (*redis.Options, error) {
+	tlsConf, err := d.conf.tls.config()
+	if err != nil {
+		return nil, err
+	}
 	ops := &redis.Options{
 		Network:            d.conf.conn.network,
 		Addr:               d.conf.conn.connectionStrings,
@@ -122,69 +329,67 @@ func (d *Datasource) getOptions() *redis.Options {
 		PoolTimeout:        d.conf.pool.poolTimeout,
 		IdleTimeout:        d.conf.pool.idleTimeout,
 		IdleCheckFrequency: d.conf.pool.idleCheckFrequency,
+		TLSConfig:          tlsConf,
 	}
-	return ops
+	return ops, nil
 }
 
 // keepalive initiates a background goroutine that periodically pings the redis server
 // to monitor connection health. Upon detecting a failure in the ping, it attempts to reconnect
-// and subsequently invokes a callback (if set) with the updated connection status. This mechanism
+// following the reconnect policy and invokes a callback (if set) with the updated connection status. This mechanism
 // ensures that the Datasource remains current with respect to the connection state.
 //
 // The ping interval is determined by the configuration's PingInterval; if it is not properly set,
-// a default interval is used.
+// a default interval is used. The routine stops when the Datasource is closed.
 func (d *Datasource) keepalive() {
 	interval := d.conf.PingInterval()
 	if interval <= 0 {
 		interval = defaultPingInterval
 	}
 	var response wrapify.R
+	d.routines.Add(1)
 	go func() {
+		defer d.routines.Done()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
-		reconnectAttempt := 0 // Initialize reconnect attempt count
-		for range ticker.C {
+		for {
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+			}
+			// In sentinel mode, follow master promotions before checking the connection health,
+			// since a demoted master may still answer pings while rejecting writes.
+			if d.conf.IsSentinel() {
+				if response, ok := d.failover(); ok {
+					d.publish(eventOf(response), stateOf(response, StateDegraded), response)
+					continue
+				}
+			}
 			ps := time.Now()
-			if err := d.ping(); err != nil {
+			err := d.ping()
+			d.metrics.observePing(time.Since(ps), err)
+			if err != nil {
 				duration := time.Since(ps)
-				response = wrapify.WrapInternalServerError("The redis server is currently unreachable. Initiating reconnection process...", nil).
+				d.publish(EventPingFailed, StateDegraded, wrapify.WrapInternalServerError("The redis server is currently unreachable. Initiating reconnection process...", nil).
 					WithDebuggingKV("redis_conn_str", d.conf.String(true)).
 					WithDebuggingKV("ping_executed_in", duration.String()).
 					WithDebuggingKV("ping_start_at", ps.Format(defaultTimeFormat)).
 					WithDebuggingKV("ping_end_at", ps.Add(duration).Format(defaultTimeFormat)).
 					WithErrSck(err).
 					WithHeader(wrapify.InternalServerError).
-					Reply()
-
-				ps = time.Now()
-				if err := d.reconnect(); err != nil {
-					duration := time.Since(ps)
-					reconnectAttempt++ // Increment reconnect count on failure reconnect
-					response = wrapify.WrapInternalServerError("The redis server remains unreachable. The reconnection attempt has failed", nil).
-						WithDebuggingKV("redis_conn_str", d.conf.String(true)).
-						WithDebuggingKV("reconnect_executed_in", duration.String()).
-						WithDebuggingKV("reconnect_start_at", ps.Format(defaultTimeFormat)).
-						WithDebuggingKV("reconnect_end_at", ps.Add(duration).Format(defaultTimeFormat)).
-						WithDebuggingKV("reconnect_attempt", reconnectAttempt).
-						WithErrSck(err).
-						WithHeader(wrapify.InternalServerError).
-						Reply()
-				} else {
-					duration := time.Since(ps)
-					reconnectAttempt = 0
-					response = wrapify.New().
-						WithStatusCode(http.StatusOK).
-						WithDebuggingKV("redis_conn_str", d.conf.String(true)).
-						WithDebuggingKV("reconnect_executed_in", duration.String()).
-						WithDebuggingKV("reconnect_start_at", ps.Format(defaultTimeFormat)).
-						WithDebuggingKV("reconnect_end_at", ps.Add(duration).Format(defaultTimeFormat)).
-						WithMessagef("The connection to the redis server has been successfully re-established: '%s'", d.conf.String(true)).
-						WithHeader(wrapify.OK).
-						Reply()
+					Reply())
+				// Reconnection follows its own policy, independently of the ping interval.
+				if !d.reconnectWithPolicy() {
+					return
 				}
+				continue
 			} else {
 				duration := time.Since(ps)
-				reconnectAttempt = 0
+				d.setLatency(duration)
+				if d.conf.IsTracking() {
+					d.keepTracking()
+				}
 				response = wrapify.New().
 					WithStatusCode(http.StatusOK).
 					WithDebuggingKV("redis_conn_str", d.conf.String(true)).
@@ -195,30 +400,67 @@ func (d *Datasource) keepalive() {
 					WithHeader(wrapify.OK).
 					Reply()
 			}
-			d.SetWrap(response)
-			d.invoke(response)
-			d.invokeReplica(response, d)
+			d.publish(EventPingOK, StateConnected, response)
 		}
 	}()
 }
 
+// publish moves the Datasource to the given state, records the given response as the current connection
+// status and publishes an event of the given type to the subscribers. When the Datasource is a replica of a
+// ReplicaSet, an EventReplicaChanged event carrying this Datasource is also published on the primary. If the
+// Settings only report transitions, no event is published when the state is unchanged.
+func (d *Datasource) publish(t EventType, state ConnState, response wrapify.R) {
+	// A closed Datasource keeps reporting its closed status, even if a keepalive tick
+	// completes after Shutdown gave up waiting for it.
+	if d.IsClosed() {
+		return
+	}
+	changed := d.transition(state, response)
+	d.SetWrap(response)
+	d.logResponse(levelOf(t), response)
+	if !changed && d.conf.IsTransitionsOnly() {
+		return
+	}
+	event := Event{Type: t, State: state, Changed: changed, Response: response, At: time.Now()}
+	d.events.publish(event)
+	if primary := d.primary; primary != nil {
+		event.Type, event.Replica = EventReplicaChanged, d
+		primary.events.publish(event)
+	}
+}
+
 // ping performs a health check on the current redis connection by issuing a ping
-// It returns an error if the connection is nil or if the ping operation fails.
+// In cluster mode, every master node is pinged. It returns an error if the connection is nil or if the ping operation fails.
 //
 // Returns:
 //   - nil if the connection is healthy;
 //   - an error if the connection is nil or the ping fails.
 func (d *Datasource) ping() error {
+	return d.pingContext(context.Background())
+}
+
+// pingContext performs the same health check as ping, giving up as soon as ctx expires or is cancelled.
+func (d *Datasource) pingContext(ctx context.Context) error {
 	d.mu.RLock()
 	conn := d.conn
+	cluster := d.cluster
+	closed := d.closed
 	d.mu.RUnlock()
+	if closed {
+		return errDatasourceClosed
+	}
+	if cluster != nil {
+		return await(ctx, func() error { return pingCluster(cluster) })
+	}
 	if conn == nil {
-		return fmt.Errorf("the redis connection is currently unavailable")
+		return errConnUnavailable
 	}
-	return conn.Ping().Err()
+	client := d.bind(ctx, conn)
+	return await(ctx, func() error { return client.Ping().Err() })
 }
 
 // reconnect attempts to establish a new connection to the redis server using the current configuration.
+// In sentinel mode, the master address is resolved again so that the new connection targets the current master.
 // If the new connection is successfully verified via ping, it replaces the existing connection in the Datasource.
 // In the event that a previous connection exists, it is closed to release associated resources.
 //
@@ -226,58 +468,87 @@ func (d *Datasource) ping() error {
 //   - nil if reconnection is successful;
 //   - an error if the reconnection fails at any stage.
 func (d *Datasource) reconnect() error {
-	ops := d.getOptions()
+	return d.reconnectContext(context.Background())
+}
+
+// reconnectContext performs the same reconnection as reconnect, giving up as soon as ctx expires
+// or is cancelled. A connection established after ctx is done is discarded.
+func (d *Datasource) reconnectContext(ctx context.Context) error {
+	if d.IsClosed() {
+		return errDatasourceClosed
+	}
+	if d.conf.IsCluster() {
+		return d.reconnectCluster(ctx)
+	}
+	var ops *redis.Options
+	err := await(ctx, func() error {
+		var err error
+		ops, err = d.resolveOptions()
+		return err
+	})
+	if err != nil {
+		return err
+	}
 	current := redis.NewClient(ops)
-	if err := current.Ping().Err(); err != nil {
+	d.instrument(current)
+	ps := time.Now()
+	if err := await(ctx, func() error { return current.Ping().Err() }); err != nil {
 		current.Close()
 		return err
 	}
+	latency := time.Since(ps)
 
 	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		current.Close()
+		return errDatasourceClosed
+	}
 	previous := d.conn
 	d.conn = current
+	d.master = ops.Addr
+	d.latency = latency
 	d.mu.Unlock()
 	if previous != nil {
 		previous.Close()
 	}
+	// The tracking session follows the connection, e.g. to the master promoted by the sentinels.
+	if d.conf.IsTracking() {
+		if err := d.track(ops); err != nil {
+			d.log(levelWarn, "The tracking session could not be established", "error", err)
+		}
+	}
 	return nil
 }
 
-// invoke safely retrieves the registered callback function and, if one is set,
-// invokes it asynchronously with the provided wrapify.R response. This ensures that
-// external consumers are notified of connection status changes without blocking the
-// calling goroutine.
-func (d *Datasource) invoke(response wrapify.R) {
-	d.mu.RLock()
-	callback := d.on
-	d.mu.RUnlock()
-	if callback != nil {
-		go callback(response)
+// await runs fn in a separate goroutine and waits for either its completion or the end of ctx.
+// The go-redis client does not observe contexts, so await lets callers stop waiting on a blocked
+// command; the command itself keeps running until it completes or hits its own timeout.
+//
+// Returns:
+//   - the context error if ctx is done before fn returns;
+//   - the error returned by fn otherwise.
+func await(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-}
-
-// invokeReplica safely retrieves the registered replica callback function and, if one is set,
-// invokes it asynchronously with the provided wrapify.R response and a pointer to the replica Datasource.
-// This ensures that external consumers are notified of replica-specific connection status changes,
-// such as replica failovers, reconnection attempts, or health updates, without blocking the calling goroutine.
-func (d *Datasource) invokeReplica(response wrapify.R, replicator *Datasource) {
-	d.mu.RLock()
-	callback := d.onReplica
-	d.mu.RUnlock()
-	if callback != nil {
-		go callback(response, replicator)
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-// notify safely retrieves the registered notifier callback function and, if one is set,
-// invokes it asynchronously with the provided wrapify.R response. This method allows the Datasource
-// to notify external components of significant events (e.g., reconnection, keepalive updates)
-// without blocking the calling goroutine, ensuring that notification handling is performed concurrently.
-func (d *Datasource) notify(response wrapify.R) {
-	d.mu.RLock()
-	callback := d.notifier
-	d.mu.RUnlock()
-	if callback != nil {
-		go callback(response)
-	}
+// cancelledResponse returns the response reported when an operation is aborted because its
+// context expired or was cancelled.
+func cancelledResponse(err error, message string) wrapify.R {
+	return wrapify.WrapRequestTimeout(message, nil).
+		WithErrSck(err).
+		WithHeader(wrapify.RequestTimeout).
+		Reply()
 }
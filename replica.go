@@ -0,0 +1,146 @@
+package redisc
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-redis/redis"
+)
+
+// readOnlyCommands lists the commands that never modify the dataset and may therefore be
+// served by a replica.
+var readOnlyCommands = map[string]bool{
+	"bitcount": true, "bitpos": true, "dbsize": true, "dump": true, "exists": true,
+	"geodist": true, "geohash": true, "geopos": true, "georadius_ro": true, "georadiusbymember_ro": true,
+	"get": true, "getbit": true, "getrange": true, "hexists": true, "hget": true,
+	"hgetall": true, "hkeys": true, "hlen": true, "hmget": true, "hscan": true,
+	"hstrlen": true, "hvals": true, "keys": true, "lindex": true, "llen": true,
+	"lrange": true, "mget": true, "object": true, "pfcount": true, "pttl": true,
+	"randomkey": true, "scan": true, "scard": true, "sdiff": true, "sinter": true,
+	"sismember": true, "smembers": true, "srandmember": true, "sscan": true, "strlen": true,
+	"sunion": true, "ttl": true, "type": true, "xlen": true, "xrange": true,
+	"xrevrange": true, "zcard": true, "zcount": true, "zlexcount": true, "zrange": true,
+	"zrangebylex": true, "zrangebyscore": true, "zrank": true, "zrevrange": true, "zrevrangebylex": true,
+	"zrevrangebyscore": true, "zrevrank": true, "zscan": true, "zscore": true,
+}
+
+// NewReplicaSet creates a ReplicaSet made of the given primary Datasource and one replica Datasource
+// per replica configuration. Every replica runs its own keepalive loop, even if its initial connection
+// fails, and reports its status changes through the onReplica callback of the primary with the
+// replica itself as the replicator. Register the callback with SetOnReplica on the primary before
+// calling NewReplicaSet to observe the initial events. The replica configurations are copied with
+// keepalive enabled, leaving the Settings of the caller unchanged.
+//
+// Parameters:
+//   - primary: the Datasource that serves write commands.
+//   - replicas: the configurations of the replica servers.
+//
+// Returns:
+//   - a ReplicaSet using the round-robin strategy by default.
+func NewReplicaSet(primary *Datasource, replicas ...Settings) *ReplicaSet {
+	r := &ReplicaSet{
+		primary:  primary,
+		strategy: ReplicaRoundRobin,
+	}
+	for i := range replicas {
+		conf := *replicas[i].clone().SetKeepalive(true)
+		replica := &Datasource{
+			conf:    conf,
+			primary: primary,
+			done:    make(chan struct{}),
+			ready:   make(chan struct{}),
+		}
+		replica.open(context.Background())
+		// open only starts the keepalive routine on success; a replica that is down at startup
+		// still needs its own loop to be picked up once it becomes reachable.
+		if conf.IsEnabled() && !conf.IsLazy() && !replica.IsConnected() {
+			replica.keepalive()
+		}
+		r.replicas = append(r.replicas, replica)
+	}
+	return r
+}
+
+// Writer returns the Datasource that serves write commands, which is always the primary.
+func (r *ReplicaSet) Writer() *Datasource {
+	return r.primary
+}
+
+// Reader returns the Datasource that should serve the next read-only command, picked among the
+// healthy replicas according to the ReplicaSet strategy. If no replica is healthy, the primary is returned.
+func (r *ReplicaSet) Reader() *Datasource {
+	healthy := r.Healthy()
+	if len(healthy) == 0 {
+		return r.primary
+	}
+	switch r.Strategy() {
+	case ReplicaLowestLatency:
+		chosen := healthy[0]
+		for _, replica := range healthy[1:] {
+			if replica.Latency() < chosen.Latency() {
+				chosen = replica
+			}
+		}
+		return chosen
+	default:
+		n := atomic.AddUint64(&r.next, 1)
+		return healthy[(n-1)%uint64(len(healthy))]
+	}
+}
+
+// Healthy returns the replicas whose last known status indicates a successful connection.
+func (r *ReplicaSet) Healthy() []*Datasource {
+	healthy := make([]*Datasource, 0, len(r.replicas))
+	for _, replica := range r.replicas {
+		if replica.IsConnected() && replica.Client() != nil {
+			healthy = append(healthy, replica)
+		}
+	}
+	return healthy
+}
+
+// Route returns the Datasource that should serve the given command: a healthy replica for
+// read-only commands, and the primary otherwise.
+func (r *ReplicaSet) Route(command string) *Datasource {
+	if IsReadOnlyCommand(command) {
+		return r.Reader()
+	}
+	return r.Writer()
+}
+
+// Do executes the given command on the Datasource selected by Route.
+// The first argument is the command name, e.g. Do("get", "key").
+//
+// Returns:
+//   - the command result; its error reports an unavailable connection if the selected
+//     Datasource is not connected.
+func (r *ReplicaSet) Do(args ...interface{}) *redis.Cmd {
+	return r.DoContext(context.Background(), args...)
+}
+
+// DoContext executes the given command on the Datasource selected by Route, giving up as soon as
+// ctx expires or is cancelled, in which case the command error is the context error.
+func (r *ReplicaSet) DoContext(ctx context.Context, args ...interface{}) *redis.Cmd {
+	var command string
+	if len(args) > 0 {
+		command, _ = args[0].(string)
+	}
+	d := r.Route(command)
+	client := d.Client()
+	if client == nil {
+		return redis.NewCmdResult(nil, errConnUnavailable)
+	}
+	client = d.bind(ctx, client)
+	cmd := redis.NewCmd(args...)
+	if err := await(ctx, func() error { return client.Process(cmd) }); err != nil && ctx.Err() != nil {
+		return redis.NewCmdResult(nil, err)
+	}
+	return cmd
+}
+
+// IsReadOnlyCommand returns true if the given command never modifies the dataset
+// and may therefore be served by a replica. The comparison is case-insensitive.
+func IsReadOnlyCommand(command string) bool {
+	return readOnlyCommands[strings.ToLower(command)]
+}
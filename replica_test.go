@@ -0,0 +1,43 @@
+package redisc
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewReplicaSetCopiesSettings(t *testing.T) {
+	conf := NewSettings()
+	conf.Conn().SetConnectionStrings("127.0.0.1:1")
+	r := NewReplicaSet(NewClient(*NewSettings()), *conf)
+	defer r.Close()
+	if conf.keepalive {
+		t.Errorf("NewReplicaSet() enabled keepalive on the Settings of the caller")
+	}
+	replica := r.Replicas()[0]
+	if !replica.conf.keepalive {
+		t.Errorf("replica keepalive = false, want true")
+	}
+	if replica.conf.conn == conf.conn {
+		t.Errorf("the replica shares its connection settings with the caller")
+	}
+}
+
+func TestReplicaSetStrategyConcurrent(t *testing.T) {
+	r := NewReplicaSet(NewClient(*NewSettings()))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			r.SetStrategy(ReplicaLowestLatency).SetStrategy(ReplicaRoundRobin)
+		}()
+		go func() {
+			defer wg.Done()
+			r.Route("get")
+		}()
+	}
+	wg.Wait()
+	if got := r.Strategy(); got != ReplicaRoundRobin {
+		t.Errorf("Strategy() = %v, want round-robin", got)
+	}
+}
@@ -0,0 +1,82 @@
+package redisc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// respError is an error reply of the server.
+type respError string
+
+// Error implements the error interface.
+func (e respError) Error() string {
+	return string(e)
+}
+
+// writeCommand encodes a command as a RESP array of bulk strings and flushes it to w.
+func writeCommand(w *bufio.Writer, args ...string) error {
+	w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		w.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+	return w.Flush()
+}
+
+// readReply decodes a RESP2 reply from r.
+//
+// Returns:
+//   - a string for simple and bulk strings, an int64 for integers, a []interface{} for arrays,
+//     a respError for errors, or nil for null bulk strings and arrays;
+//   - an error if the reply cannot be read or is malformed.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed redis reply: %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return respError(value), nil
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis bulk string length: %q", value)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		return string(b[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed redis array length: %q", value)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, errors.New("unsupported redis reply type: " + strconv.QuoteRune(rune(kind)))
+}
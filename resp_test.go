@@ -0,0 +1,95 @@
+package redisc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  interface{}
+	}{
+		{"simple string", "+OK\r\n", "OK"},
+		{"empty simple string", "+\r\n", ""},
+		{"error", "-ERR unknown command\r\n", respError("ERR unknown command")},
+		{"integer", ":42\r\n", int64(42)},
+		{"negative integer", ":-1\r\n", int64(-1)},
+		{"bulk string", "$5\r\nhello\r\n", "hello"},
+		{"bulk string with CRLF", "$7\r\nab\r\ncde\r\n", "ab\r\ncde"},
+		{"empty bulk string", "$0\r\n\r\n", ""},
+		{"null bulk string", "$-1\r\n", nil},
+		{"empty array", "*0\r\n", []interface{}{}},
+		{"null array", "*-1\r\n", nil},
+		{"invalidation push", "*2\r\n$10\r\ninvalidate\r\n*2\r\n$1\r\na\r\n$1\r\nb\r\n", []interface{}{
+			"invalidate", []interface{}{"a", "b"},
+		}},
+		{"mixed array", "*4\r\n:1\r\n$-1\r\n+OK\r\n-ERR\r\n", []interface{}{int64(1), nil, "OK", respError("ERR")}},
+	}
+	for _, tt := range tests {
+		got, err := readReply(bufio.NewReader(strings.NewReader(tt.reply)))
+		if err != nil {
+			t.Errorf("%s: readReply(%q) error = %v", tt.name, tt.reply, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: readReply(%q) = %#v, want %#v", tt.name, tt.reply, got, tt.want)
+		}
+	}
+}
+
+func TestReadReplySequence(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("+OK\r\n:7\r\n$3\r\nfoo\r\n"))
+	for _, want := range []interface{}{"OK", int64(7), "foo"} {
+		got, err := readReply(r)
+		if err != nil || got != want {
+			t.Fatalf("readReply() = %#v, %v, want %#v", got, err, want)
+		}
+	}
+	if _, err := readReply(r); err != io.EOF {
+		t.Errorf("readReply() error = %v at the end of the stream, want EOF", err)
+	}
+}
+
+func TestReadReplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  string
+	}{
+		{"missing CR", "+OK\n", "malformed redis reply"},
+		{"empty line", "\r\n", "malformed redis reply"},
+		{"unsupported type", "%1\r\n", "unsupported redis reply type: '%'"},
+		{"invalid integer", ":abc\r\n", "invalid syntax"},
+		{"invalid bulk length", "$x\r\n", "malformed redis bulk string length"},
+		{"invalid array length", "*x\r\n", "malformed redis array length"},
+		{"truncated bulk string", "$10\r\nhello\r\n", "unexpected EOF"},
+		{"truncated array", "*2\r\n+OK\r\n", "EOF"},
+	}
+	for _, tt := range tests {
+		_, err := readReply(bufio.NewReader(strings.NewReader(tt.reply)))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: readReply(%q) error = %v, want %q", tt.name, tt.reply, err, tt.want)
+		}
+	}
+}
+
+func TestWriteCommand(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCommand(bufio.NewWriter(&buf), "CLIENT", "TRACKING", "on", ""); err != nil {
+		t.Fatalf("writeCommand() error = %v", err)
+	}
+	want := "*4\r\n$6\r\nCLIENT\r\n$8\r\nTRACKING\r\n$2\r\non\r\n$0\r\n\r\n"
+	if buf.String() != want {
+		t.Errorf("writeCommand() wrote %q, want %q", buf.String(), want)
+	}
+	got, err := readReply(bufio.NewReader(&buf))
+	if err != nil || !reflect.DeepEqual(got, []interface{}{"CLIENT", "TRACKING", "on", ""}) {
+		t.Errorf("readReply() = %#v, %v, want the written command", got, err)
+	}
+}
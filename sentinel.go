@@ -0,0 +1,115 @@
+package redisc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/unify4g"
+	"github.com/sivaosorg/wrapify"
+)
+
+// resolveOptions builds the redis.Options from the current configuration and, when sentinel mode
+// is enabled, replaces the configured address with the master address reported by the sentinels.
+//
+// Returns:
+//   - the options ready to be used by redis.NewClient;
+//   - an error if the TLS configuration cannot be loaded or the master address could not be resolved.
+func (d *Datasource) resolveOptions() (*redis.Options, error) {
+	ops, err := d.getOptions()
+	if err != nil {
+		return nil, err
+	}
+	if !d.conf.IsSentinel() {
+		return ops, nil
+	}
+	addr, err := d.masterAddr()
+	if err != nil {
+		return nil, err
+	}
+	ops.Network = "tcp"
+	ops.Addr = addr
+	return ops, nil
+}
+
+// masterAddr queries the configured sentinels, in order, for the address of the master
+// monitored under the configured master name. The first sentinel that answers wins.
+//
+// Returns:
+//   - the master address in "host:port" format;
+//   - an error if the sentinel settings are incomplete or no sentinel could resolve the master.
+func (d *Datasource) masterAddr() (string, error) {
+	s := d.conf.sentinel
+	if unify4g.IsEmpty(s.masterName) {
+		return "", fmt.Errorf("the redis sentinel master name is required")
+	}
+	if len(s.addrs) == 0 {
+		return "", fmt.Errorf("at least one redis sentinel address is required")
+	}
+	var last error
+	for _, addr := range s.addrs {
+		sentinel := redis.NewSentinelClient(&redis.Options{
+			Addr:         addr,
+			Password:     s.password,
+			DialTimeout:  d.conf.timeout.connTimeout,
+			ReadTimeout:  d.conf.timeout.readTimeout,
+			WriteTimeout: d.conf.timeout.writeTimeout,
+		})
+		result, err := sentinel.GetMasterAddrByName(s.masterName).Result()
+		sentinel.Close()
+		if err != nil {
+			last = err
+			continue
+		}
+		if len(result) != 2 {
+			last = fmt.Errorf("the redis sentinel '%s' returned an unexpected master address: %v", addr, result)
+			continue
+		}
+		return net.JoinHostPort(result[0], result[1]), nil
+	}
+	return "", fmt.Errorf("none of the redis sentinels could resolve master '%s': %v", s.masterName, last)
+}
+
+// failover checks whether the sentinels report a master different from the one currently in use.
+// When a promotion is detected, the connection is re-established against the new master and a
+// response describing the transition, including the previous and current master addresses, is returned.
+//
+// Returns:
+//   - the response describing the failover and true if a promotion was detected;
+//   - an empty response and false if the master is unchanged or could not be resolved.
+func (d *Datasource) failover() (wrapify.R, bool) {
+	previous := d.Master()
+	current, err := d.masterAddr()
+	if err != nil || current == previous {
+		return wrapify.R{}, false
+	}
+	ps := time.Now()
+	err = d.reconnect()
+	d.metrics.observeReconnect(err)
+	if err != nil {
+		duration := time.Since(ps)
+		return wrapify.WrapInternalServerError("", nil).
+			WithMessagef("The redis sentinel promoted a new master '%s', but the connection could not be switched", current).
+			WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+			WithDebuggingKV("sentinel_master_name", d.conf.sentinel.masterName).
+			WithDebuggingKV("sentinel_master_previous", previous).
+			WithDebuggingKV("sentinel_master_current", current).
+			WithDebuggingKV("failover_executed_in", duration.String()).
+			WithErrSck(err).
+			WithHeader(wrapify.InternalServerError).
+			Reply(), true
+	}
+	duration := time.Since(ps)
+	return wrapify.New().
+		WithStatusCode(http.StatusOK).
+		WithDebuggingKV("redis_conn_str", d.conf.String(true)).
+		WithDebuggingKV("sentinel_master_name", d.conf.sentinel.masterName).
+		WithDebuggingKV("sentinel_master_previous", previous).
+		WithDebuggingKV("sentinel_master_current", d.Master()).
+		WithDebuggingKV("failover_executed_in", duration.String()).
+		WithMessagef("The redis sentinel failover has been followed: '%s' -> '%s'", previous, d.Master()).
+		WithHeader(wrapify.OK).
+		Reply(), true
+}
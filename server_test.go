@@ -0,0 +1,295 @@
+package redisc
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeScript emulates a Lua script on the fakeServer, which holds its lock while the script runs.
+type fakeScript func(s *fakeServer, keys, argv []string) interface{}
+
+// fakeStatus is a simple string reply of the fakeServer, such as OK.
+type fakeStatus string
+
+// fakeServer is an in-memory redis server implementing the few commands the tests need, over RESP2.
+// The scripts are emulated by the functions registered for their SHA1 digest in scripts.
+type fakeServer struct {
+	addr string
+	mu   sync.Mutex
+	// The string values and sorted sets stored, by key.
+	values map[string]string
+	zsets  map[string]map[string]float64
+	// The expiration of the keys, by key.
+	expires map[string]time.Time
+	// The emulation of the scripts, by SHA1 digest of their source.
+	scripts map[string]fakeScript
+	// The errors replied to the commands instead of running them, by upper-case command name.
+	errors map[string]string
+	// The names of the commands received, in upper case.
+	commands []string
+}
+
+// newFakeServer starts a fakeServer on a random local port, stopped at the end of the test.
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeServer{
+		addr:    l.Addr().String(),
+		values:  make(map[string]string),
+		zsets:   make(map[string]map[string]float64),
+		expires: make(map[string]time.Time),
+		scripts: make(map[string]fakeScript),
+		errors:  make(map[string]string),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	conns := make(map[net.Conn]bool)
+	t.Cleanup(func() {
+		l.Close()
+		mu.Lock()
+		for conn := range conns {
+			conn.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns[conn] = true
+			mu.Unlock()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s
+}
+
+// newFakeDatasource returns a Datasource connected to s.
+func newFakeDatasource(t *testing.T, s *fakeServer) *Datasource {
+	t.Helper()
+	conf := NewSettings().SetEnable(true)
+	conf.Conn().SetConnectionStrings(s.addr)
+	d := NewClient(*conf)
+	t.Cleanup(func() { d.Close() })
+	if !d.IsConnected() {
+		t.Fatalf("not connected to the fake server: %s", d.Wrap().Message())
+	}
+	return d
+}
+
+// serve replies to the commands received on conn until it is closed.
+func (s *fakeServer) serve(conn net.Conn) {
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		request, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := request.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+		writeFakeReply(w, s.do(args))
+		if w.Flush() != nil {
+			return
+		}
+	}
+}
+
+// do runs the command described by args.
+func (s *fakeServer) do(args []string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := strings.ToUpper(args[0])
+	s.commands = append(s.commands, name)
+	if message, ok := s.errors[name]; ok {
+		return respError(message)
+	}
+	for key, at := range s.expires {
+		if !time.Now().Before(at) {
+			s.delete(key)
+		}
+	}
+	switch name {
+	case "PING":
+		return fakeStatus("PONG")
+	case "GET":
+		if value, ok := s.values[args[1]]; ok {
+			return value
+		}
+		return nil
+	case "SET":
+		return s.set(args[1:])
+	case "DEL":
+		var n int64
+		for _, key := range args[1:] {
+			if s.delete(key) {
+				n++
+			}
+		}
+		return n
+	case "PTTL":
+		if at, ok := s.expires[args[1]]; ok {
+			return int64(time.Until(at) / time.Millisecond)
+		}
+		if _, ok := s.values[args[1]]; ok {
+			return int64(-1)
+		}
+		return int64(-2)
+	case "ZRANGE":
+		var members []interface{}
+		for member := range s.zsets[args[1]] {
+			members = append(members, member)
+		}
+		return members
+	case "PUBLISH":
+		return int64(0)
+	case "EVALSHA":
+		return respError("NOSCRIPT No matching script. Please use EVAL.")
+	case "EVAL":
+		digest := sha1.Sum([]byte(args[1]))
+		script, ok := s.scripts[hex.EncodeToString(digest[:])]
+		if !ok {
+			return respError("ERR unknown script")
+		}
+		n, _ := strconv.Atoi(args[2])
+		return script(s, args[3:3+n], args[3+n:])
+	}
+	return respError("ERR unknown command '" + args[0] + "'")
+}
+
+// set runs the SET command with the arguments args, supporting the PX and NX options.
+func (s *fakeServer) set(args []string) interface{} {
+	key, value := args[0], args[1]
+	var ttl time.Duration
+	nx := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "PX":
+			ms, _ := strconv.Atoi(args[i+1])
+			ttl = time.Duration(ms) * time.Millisecond
+			i++
+		case "EX":
+			sec, _ := strconv.Atoi(args[i+1])
+			ttl = time.Duration(sec) * time.Second
+			i++
+		}
+	}
+	if _, exists := s.values[key]; exists && nx {
+		return nil
+	}
+	s.store(key, value, ttl)
+	return fakeStatus("OK")
+}
+
+// store stores value under key, expiring after ttl if positive. The caller holds s.mu.
+func (s *fakeServer) store(key, value string, ttl time.Duration) {
+	s.values[key] = value
+	delete(s.expires, key)
+	if ttl > 0 {
+		s.expires[key] = time.Now().Add(ttl)
+	}
+}
+
+// delete removes key, reporting whether it existed. The caller holds s.mu.
+func (s *fakeServer) delete(key string) bool {
+	_, value := s.values[key]
+	_, zset := s.zsets[key]
+	delete(s.values, key)
+	delete(s.zsets, key)
+	delete(s.expires, key)
+	return value || zset
+}
+
+// Set stores value under key, expiring after ttl if positive.
+func (s *fakeServer) Set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(key, value, ttl)
+}
+
+// Get returns the value stored under key and whether it exists.
+func (s *fakeServer) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Count returns the number of commands received with the given upper-case name.
+func (s *fakeServer) Count(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, command := range s.commands {
+		if command == name {
+			n++
+		}
+	}
+	return n
+}
+
+// Script registers the emulation of the script of the given SHA1 digest, as returned by redis.Script.Hash.
+func (s *fakeServer) Script(hash string, script fakeScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[hash] = script
+}
+
+// Fail makes the server reply the error message to every command of the given upper-case name,
+// or run it again if message is empty.
+func (s *fakeServer) Fail(name, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message == "" {
+		delete(s.errors, name)
+	} else {
+		s.errors[name] = message
+	}
+}
+
+// writeFakeReply encodes reply in RESP2 to w.
+func writeFakeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case fakeStatus:
+		w.WriteString("+" + string(v) + "\r\n")
+	case respError:
+		w.WriteString("-" + string(v) + "\r\n")
+	case int64:
+		w.WriteString(":" + strconv.FormatInt(v, 10) + "\r\n")
+	case string:
+		w.WriteString("$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n")
+	case []interface{}:
+		w.WriteString("*" + strconv.Itoa(len(v)) + "\r\n")
+		for _, item := range v {
+			writeFakeReply(w, item)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package redisc
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// SlowLog returns the n most recent slow commands measured by the Datasource, most recent first,
+// or every retained command if n is not positive. A command is slow when its round-trip time
+// reaches the threshold set by Settings.SetSlowThreshold.
+func (d *Datasource) SlowLog(n int) []SlowLogEntry {
+	return d.slowLog.latest(n)
+}
+
+// SlowLogWithServer returns the n most recent slow commands, most recent first, merging those measured
+// by the Datasource with the n most recent entries reported by the SLOWLOG GET command of the server
+// (of every master node in cluster mode). The server is queried within the deadline of ctx.
+//
+// Returns:
+//   - the merged entries;
+//   - an error if the server could not be queried, in which case only the client entries are returned.
+func (d *Datasource) SlowLogWithServer(ctx context.Context, n int) ([]SlowLogEntry, error) {
+	entries := d.SlowLog(n)
+	if n <= 0 {
+		n = d.slowLogCapacity()
+	}
+	var server []SlowLogEntry
+	var mu sync.Mutex
+	query := func(client *redis.Client) error {
+		nodeEntries, err := serverSlowLog(client, n)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		server = append(server, nodeEntries...)
+		return nil
+	}
+	var err error
+	if cluster := d.ClusterConn(); cluster != nil {
+		err = await(ctx, func() error { return cluster.ForEachMaster(query) })
+	} else if conn := d.Conn(); conn != nil {
+		// A bound copy of a *redis.Client is a *redis.Client.
+		client := d.bind(ctx, conn).(*redis.Client)
+		err = await(ctx, func() error { return query(client) })
+	} else {
+		err = errConnUnavailable
+	}
+	if err != nil {
+		return entries, err
+	}
+	mu.Lock()
+	entries = append(entries, server...)
+	mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
+
+// observeSlow records the command in the slow log and logs it if its duration reaches the slow threshold.
+// The caller is the one of the call the command is sent for, if any.
+func (d *Datasource) observeSlow(cmd redis.Cmder, start time.Time, duration time.Duration, c *call) {
+	threshold := d.conf.SlowThreshold()
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+	entry := SlowLogEntry{
+		Source:   SlowLogClient,
+		Time:     start,
+		Command:  cmd.Name(),
+		Duration: duration,
+		Caller:   c.caller(),
+	}
+	if args := cmd.Args(); len(args) > 1 {
+		entry.Key = fmt.Sprint(args[1])
+	}
+	d.slowLog.add(entry, d.slowLogCapacity())
+	d.log(levelWarn, "Slow redis command",
+		"redis_conn_str", d.conf.String(true),
+		"command", entry.Command,
+		"key", entry.Key,
+		"executed_in", duration.String(),
+		"slow_threshold", threshold.String(),
+		"caller", entry.Caller)
+}
+
+// slowLogCapacity returns the number of slow commands retained by the Datasource.
+func (d *Datasource) slowLogCapacity() int {
+	if size := d.conf.SlowLogSize(); size > 0 {
+		return size
+	}
+	return defaultSlowLogSize
+}
+
+// add appends the entry to the ring buffer, overwriting the oldest entry once capacity is reached.
+func (s *slowLog) add(entry SlowLogEntry, capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) < capacity {
+		s.entries = append(s.entries, entry)
+		return
+	}
+	s.entries[s.next%len(s.entries)] = entry
+	s.next = (s.next + 1) % len(s.entries)
+}
+
+// latest returns the n most recent entries, most recent first, or every entry if n is not positive.
+func (s *slowLog) latest(n int) []SlowLogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size := len(s.entries)
+	if n <= 0 || n > size {
+		n = size
+	}
+	entries := make([]SlowLogEntry, 0, n)
+	for i := 1; i <= n; i++ {
+		entries = append(entries, s.entries[(s.next-i+size)%size])
+	}
+	return entries
+}
+
+// serverSlowLog fetches the n most recent entries of the SLOWLOG of the server behind client.
+func serverSlowLog(client *redis.Client, n int) ([]SlowLogEntry, error) {
+	cmd := redis.NewSliceCmd("slowlog", "get", n)
+	if err := client.Process(cmd); err != nil {
+		return nil, err
+	}
+	addr := client.Options().Addr
+	var entries []SlowLogEntry
+	for _, item := range cmd.Val() {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) < 4 {
+			return nil, fmt.Errorf("unexpected SLOWLOG GET entry from '%s': %v", addr, item)
+		}
+		id, _ := fields[0].(int64)
+		timestamp, _ := fields[1].(int64)
+		micros, _ := fields[2].(int64)
+		entry := SlowLogEntry{
+			Source:   SlowLogServer,
+			ID:       id,
+			Time:     time.Unix(timestamp, 0),
+			Duration: time.Duration(micros) * time.Microsecond,
+			Server:   addr,
+		}
+		if args, ok := fields[3].([]interface{}); ok {
+			if len(args) > 0 {
+				entry.Command = strings.ToLower(fmt.Sprint(args[0]))
+			}
+			if len(args) > 1 {
+				entry.Key = fmt.Sprint(args[1])
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// callers returns the program counters of the stack of the current goroutine, from its caller upwards.
+func callers() []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(3, pcs)]
+}
+
+// caller returns the location ("file:line") of the first frame of the stack outside of redisc and go-redis.
+func caller(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/go-redis/redis") &&
+			!strings.HasPrefix(frame.Function, "github.com/sivaosorg/redisc.") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package redisc_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sivaosorg/redisc"
+)
+
+// pongServer starts a server answering +PONG to every command, until the test ends.
+func pongServer(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "*") {
+						conn.Write([]byte("+PONG\r\n"))
+					}
+				}
+			}(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestSlowLogCaller(t *testing.T) {
+	s := redisc.NewSettings().SetEnable(true).SetSlowThreshold(time.Nanosecond)
+	s.Conn().SetConnectionStrings(pongServer(t))
+	d := redisc.NewClient(*s)
+	defer d.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := d.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+	cache := redisc.NewCache[string](d, redisc.RawCodec{})
+
+	tests := []struct {
+		name string
+		run  func()
+		keys []string
+	}{
+		{"command", func() { cache.Get("a") }, []string{"a"}},
+		{"pipeline", func() { cache.MGet("b", "c") }, []string{"b", "c"}},
+	}
+	for _, tt := range tests {
+		tt.run()
+		found := make(map[string]redisc.SlowLogEntry)
+		for _, entry := range d.SlowLog(0) {
+			found[entry.Key] = entry
+		}
+		for _, key := range tt.keys {
+			entry, ok := found[key]
+			if !ok {
+				t.Errorf("%s: no slow log entry for the key %q", tt.name, key)
+				continue
+			}
+			if entry.Command != "get" {
+				t.Errorf("%s: command = %q, want get", tt.name, entry.Command)
+			}
+			if file := strings.SplitN(filepath.Base(entry.Caller), ":", 2)[0]; file != "slowlog_test.go" {
+				t.Errorf("%s: caller = %q, want a location in slowlog_test.go", tt.name, entry.Caller)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package redisc
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/sivaosorg/wrapify"
+)
+
+// String returns the name of the connection state, e.g. "connected".
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDegraded:
+		return "degraded"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosed:
+		return "closed"
+	}
+	return "state(" + strconv.Itoa(int(s)) + ")"
+}
+
+// MarshalJSON encodes the connection state as its name.
+func (s ConnState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// State returns the current state of the connection in a thread-safe manner.
+func (d *Datasource) State() ConnState {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.state
+}
+
+// Transitions returns a copy of the most recent state transitions of the Datasource, oldest first.
+// At most defaultStateHistorySize transitions are kept.
+func (d *Datasource) Transitions() []StateTransition {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	history := make([]StateTransition, len(d.history))
+	copy(history, d.history)
+	return history
+}
+
+// transition moves the Datasource to the given state and records the transition in the history,
+// using the message of the response as its reason. A closed Datasource keeps its closed state.
+//
+// Returns:
+//   - true if the state has changed;
+//   - false if the Datasource was already in the given state, or is closed.
+func (d *Datasource) transition(state ConnState, response wrapify.R) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.transitionLocked(state, response.Message())
+}
+
+// settle moves the Datasource to the given state, records the given response as the current
+// connection status and logs it at the given level, without publishing any event.
+func (d *Datasource) settle(level logLevel, state ConnState, response wrapify.R) {
+	d.transition(state, response)
+	d.SetWrap(response)
+	d.logResponse(level, response)
+}
+
+// eventOf returns EventReconnected if the response of a reconnection is successful, or EventReconnectFailed otherwise.
+func eventOf(response wrapify.R) EventType {
+	if response.IsSuccess() {
+		return EventReconnected
+	}
+	return EventReconnectFailed
+}
+
+// stateOf returns StateConnected if the response is successful, or failed otherwise.
+func stateOf(response wrapify.R, failed ConnState) ConnState {
+	if response.IsSuccess() {
+		return StateConnected
+	}
+	return failed
+}
+
+// transitionLocked is the lock-free part of transition; the caller must hold d.mu for writing.
+func (d *Datasource) transitionLocked(state ConnState, reason string) bool {
+	if d.state == state || d.state == StateClosed {
+		return false
+	}
+	d.history = append(d.history, StateTransition{From: d.state, To: state, At: time.Now(), Reason: reason})
+	if len(d.history) > defaultStateHistorySize {
+		d.history = d.history[len(d.history)-defaultStateHistorySize:]
+	}
+	d.state = state
+	if state == StateConnected {
+		d.signalReadyLocked(nil)
+	}
+	return true
+}
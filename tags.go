@@ -0,0 +1,204 @@
+package redisc
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sivaosorg/wrapify"
+)
+
+// tagSetScript stores a value and records its key in the sorted set of every tag, scored by the expiration
+// of the key. The members that have expired are pruned and each tag set expires along with its last member,
+// so that the tag sets do not grow forever. The expirations are computed from the clock of the server, so
+// that the clocks of the clients do not need to agree.
+//
+// KEYS[1] is the key, KEYS[2..n] the tag sets; ARGV[1] is the value and ARGV[2] the ttl in milliseconds
+// (0 for none). TIME being non-deterministic, the script is replicated by its effects.
+var tagSetScript = redis.NewScript(`
+redis.replicate_commands()
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+local expiresAt = "+inf"
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+	expiresAt = string.format("%d", now + ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	redis.call("ZADD", KEYS[i], expiresAt, KEYS[1])
+	redis.call("ZREMRANGEBYSCORE", KEYS[i], "-inf", "(" .. string.format("%d", now))
+	local last = redis.call("ZRANGE", KEYS[i], -1, -1, "WITHSCORES")
+	if last[2] == "inf" then
+		redis.call("PERSIST", KEYS[i])
+	else
+		redis.call("PEXPIREAT", KEYS[i], last[2])
+	end
+end
+return 1`)
+
+// tagInvalidateScript removes the given tag sets along with the keys they record, provided that every key
+// they record is declared: a script may only access the keys it declares, and the tagged keys are only
+// known once the tag sets have been read. Nothing is removed if a key has been tagged since, in which case
+// the caller reads the tag sets and runs the script again.
+//
+// KEYS[1..n] are the tag sets and KEYS[n+1..] the keys read from them; ARGV[1] is n.
+//
+// Returns the keys that existed and have been removed, or nil if the tag sets have changed.
+var tagInvalidateScript = redis.NewScript(`
+local n = tonumber(ARGV[1])
+local declared = {}
+for i = n + 1, #KEYS do
+	declared[KEYS[i]] = true
+end
+for i = 1, n do
+	local tagged = redis.call("ZRANGE", KEYS[i], 0, -1)
+	for j = 1, #tagged do
+		if not declared[tagged[j]] then
+			return false
+		end
+	end
+end
+local deleted = {}
+for i = n + 1, #KEYS do
+	if redis.call("DEL", KEYS[i]) == 1 then
+		deleted[#deleted + 1] = KEYS[i]
+	end
+end
+for i = 1, n do
+	redis.call("DEL", KEYS[i])
+end
+return deleted`)
+
+// InvalidateTags removes every key tagged with any of the given tags.
+// It is equivalent to InvalidateTagsContext with a background context.
+func (c *Cache[T]) InvalidateTags(tags ...string) wrapify.R {
+	return c.InvalidateTagsContext(context.Background(), tags...)
+}
+
+// InvalidateTagsContext removes every key tagged with any of the given tags (see SetContext), along with
+// the tags themselves, giving up as soon as ctx expires or is cancelled. The body of the response holds
+// the number of keys removed.
+//
+// The keys recorded by the tags are read, then removed atomically along with the tags, provided that no
+// key has been tagged in between; otherwise they are read again, up to defaultTagInvalidationAttempts times.
+//
+// The tags are stored in redis under "redisc:tag:<prefix><tag>" as sorted sets. In cluster mode, the keys
+// and their tags must belong to the same hash slot, e.g. by using a prefix with a hash tag such as "{users}:".
+func (c *Cache[T]) InvalidateTagsContext(ctx context.Context, tags ...string) wrapify.R {
+	deleted, response := c.invalidateTags(ctx, tags)
+	if deleted == nil {
+		return response
+	}
+	return wrapify.WrapOk("", len(deleted)).
+		WithMessagef("Successfully removed %d keys tagged %v", len(deleted), tags).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// invalidateTags removes every key tagged with any of the given tags.
+//
+// Returns:
+//   - the keys removed, with their prefix, and an empty response;
+//   - nil and an error response otherwise.
+func (c *Cache[T]) invalidateTags(ctx context.Context, tags []string) ([]string, wrapify.R) {
+	client, response, ok := c.client(ctx)
+	if !ok {
+		return nil, response
+	}
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = c.tagKey(tag)
+	}
+	var deleted []string
+	err := await(ctx, func() error {
+		for attempt := 0; attempt < defaultTagInvalidationAttempts && ctx.Err() == nil; attempt++ {
+			members, err := tagMembers(client, tagKeys)
+			if err != nil {
+				return err
+			}
+			keys := append(append(make([]string, 0, len(tagKeys)+len(members)), tagKeys...), members...)
+			result, err := tagInvalidateScript.Run(client, keys, len(tagKeys)).Result()
+			if err == redis.Nil {
+				// A key has been tagged since the tag sets were read.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			items, _ := result.([]interface{})
+			deleted = make([]string, 0, len(items))
+			for _, item := range items {
+				if key, ok := item.(string); ok {
+					deleted = append(deleted, key)
+				}
+			}
+			return nil
+		}
+		return errTagsChanged
+	})
+	if err != nil {
+		return nil, c.fail(ctx, err, "cache_invalidate_tags", "", "Failed to remove the tagged keys")
+	}
+	return deleted, wrapify.R{}
+}
+
+// tagMembers reads the keys recorded by the given tag sets, without duplicates.
+func tagMembers(client redis.UniversalClient, tagKeys []string) ([]string, error) {
+	var ranges []*redis.StringSliceCmd
+	_, err := client.Pipelined(func(pipe redis.Pipeliner) error {
+		for _, tagKey := range tagKeys {
+			ranges = append(ranges, pipe.ZRange(tagKey, 0, -1))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var members []string
+	for _, r := range ranges {
+		for _, member := range r.Val() {
+			if !seen[member] {
+				seen[member] = true
+				members = append(members, member)
+			}
+		}
+	}
+	return members, nil
+}
+
+// setTagged stores the encoded value under key, expiring after ttl, and records it in the given tags.
+func (c *Cache[T]) setTagged(ctx context.Context, client redis.UniversalClient, key string, data []byte, ttl time.Duration, tags []string) wrapify.R {
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, c.key(key))
+	for _, tag := range tags {
+		keys = append(keys, c.tagKey(tag))
+	}
+	err := await(ctx, func() error {
+		return tagSetScript.Run(client, keys, data, milliseconds(ttl)).Err()
+	})
+	if err != nil {
+		return c.fail(ctx, err, "cache_set", key, "Failed to store the key '%s'")
+	}
+	return wrapify.WrapOk("", nil).
+		WithMessagef("Successfully stored the key '%s' tagged %v", key, tags).
+		WithHeader(wrapify.OK).
+		Reply()
+}
+
+// tagKey returns the redis key of the sorted set recording the keys tagged with tag.
+func (c *Cache[T]) tagKey(tag string) string {
+	return defaultTagKeyPrefix + c.prefix + tag
+}
+
+// milliseconds returns ttl in milliseconds, rounding a positive ttl below a millisecond up to 1 as the
+// SET command of go-redis does, rather than down to 0 which stores the key without expiration.
+func milliseconds(ttl time.Duration) int64 {
+	if ttl > 0 && ttl < time.Millisecond {
+		return 1
+	}
+	return ttl.Milliseconds()
+}
@@ -0,0 +1,148 @@
+package redisc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// emulateTagScripts registers on s the emulation of the scripts of the tags. Before every run of
+// tagInvalidateScript, tagged is called with the number of runs so far, e.g. to tag keys concurrently.
+func emulateTagScripts(s *fakeServer, tagged func(s *fakeServer, run int)) {
+	s.Script(tagSetScript.Hash(), func(s *fakeServer, keys, argv []string) interface{} {
+		ttl, _ := strconv.Atoi(argv[1])
+		s.store(keys[0], argv[0], time.Duration(ttl)*time.Millisecond)
+		for _, tagKey := range keys[1:] {
+			s.tag(tagKey, keys[0])
+		}
+		return int64(1)
+	})
+	runs := 0
+	s.Script(tagInvalidateScript.Hash(), func(s *fakeServer, keys, argv []string) interface{} {
+		if tagged != nil {
+			tagged(s, runs)
+		}
+		runs++
+		n, _ := strconv.Atoi(argv[0])
+		declared := make(map[string]bool)
+		for _, key := range keys[n:] {
+			declared[key] = true
+		}
+		for _, tagKey := range keys[:n] {
+			for member := range s.zsets[tagKey] {
+				if !declared[member] {
+					return nil
+				}
+			}
+		}
+		deleted := []interface{}{}
+		for _, key := range keys[n:] {
+			if s.delete(key) {
+				deleted = append(deleted, key)
+			}
+		}
+		for _, tagKey := range keys[:n] {
+			s.delete(tagKey)
+		}
+		return deleted
+	})
+}
+
+// tag records key in the sorted set tagKey. The caller holds s.mu.
+func (s *fakeServer) tag(tagKey, key string) {
+	if s.zsets[tagKey] == nil {
+		s.zsets[tagKey] = make(map[string]float64)
+	}
+	s.zsets[tagKey][key] = 0
+}
+
+func TestInvalidateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tagged  func(s *fakeServer, run int)
+		want    []string
+		kept    []string
+		wantErr error
+	}{
+		{"unchanged tags", nil, []string{"u:1", "u:2"}, []string{"u:3"}, nil},
+		{"key tagged in between", func(s *fakeServer, run int) {
+			if run == 0 {
+				s.store("u:3", `"c"`, 0)
+				s.tag(defaultTagKeyPrefix+"u:team", "u:3")
+			}
+		}, []string{"u:1", "u:2", "u:3"}, nil, nil},
+		{"tags always changing", func(s *fakeServer, run int) {
+			key := "u:new" + strconv.Itoa(run)
+			s.store(key, `"x"`, 0)
+			s.tag(defaultTagKeyPrefix+"u:team", key)
+		}, nil, []string{"u:1", "u:2", "u:3"}, errTagsChanged},
+	}
+	for _, tt := range tests {
+		s := newFakeServer(t)
+		emulateTagScripts(s, tt.tagged)
+		c := NewCache[string](newFakeDatasource(t, s), nil).SetPrefix("u:")
+		c.Set("1", "a", time.Minute, "team", "user")
+		c.Set("2", "b", 0, "team")
+		c.Set("3", "c", 0)
+
+		deleted, response := c.invalidateTags(context.Background(), []string{"team", "user"})
+		if tt.wantErr != nil {
+			if !errors.Is(response.Cause(), tt.wantErr) {
+				t.Errorf("%s: invalidateTags() error = %v, want %v", tt.name, response.Cause(), tt.wantErr)
+			}
+		} else if response.StatusCode() != 0 {
+			t.Errorf("%s: invalidateTags() failed: %v", tt.name, response.Cause())
+		}
+		sort.Strings(deleted)
+		if strings.Join(deleted, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: deleted %q, want %q", tt.name, deleted, tt.want)
+		}
+		for _, key := range tt.want {
+			if _, ok := s.Get(key); ok {
+				t.Errorf("%s: key %q not deleted", tt.name, key)
+			}
+		}
+		for _, key := range tt.kept {
+			if _, ok := s.Get(key); !ok {
+				t.Errorf("%s: key %q deleted", tt.name, key)
+			}
+		}
+	}
+}
+
+func TestInvalidateTagsResponse(t *testing.T) {
+	s := newFakeServer(t)
+	emulateTagScripts(s, nil)
+	c := NewCache[string](newFakeDatasource(t, s), nil)
+	c.Set("a", "1", 0, "t")
+	if response := c.InvalidateTags("t"); response.StatusCode() != http.StatusOK || response.Body() != 1 {
+		t.Errorf("InvalidateTags() = %d, %v, want 200 and 1 key", response.StatusCode(), response.Body())
+	}
+	if response := c.InvalidateTags("t"); response.StatusCode() != http.StatusOK || response.Body() != 0 {
+		t.Errorf("InvalidateTags() = %d, %v, want 200 and no key", response.StatusCode(), response.Body())
+	}
+}
+
+func TestMilliseconds(t *testing.T) {
+	tests := []struct {
+		ttl  time.Duration
+		want int64
+	}{
+		{0, 0},
+		{time.Nanosecond, 1},
+		{999 * time.Microsecond, 1},
+		{time.Millisecond, 1},
+		{1500 * time.Microsecond, 1},
+		{time.Minute, 60000},
+	}
+	for _, tt := range tests {
+		if got := milliseconds(tt.ttl); got != tt.want {
+			t.Errorf("milliseconds(%v) = %d, want %d", tt.ttl, got, tt.want)
+		}
+	}
+}
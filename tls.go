@@ -0,0 +1,48 @@
+package redisc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/sivaosorg/unify4g"
+)
+
+// config builds the tls.Config described by the TLS settings.
+// The CA bundle and the client key pair are read from disk on every call, so that rotated
+// certificates are picked up by the next reconnection.
+//
+// Returns:
+//   - nil and no error if TLS is disabled;
+//   - the tls.Config to use for the connection;
+//   - an error if a certificate file cannot be read or parsed.
+func (t *tlsSettings) config() (*tls.Config, error) {
+	if t == nil || !t.enabled {
+		return nil, nil
+	}
+	conf := &tls.Config{
+		ServerName:         t.serverName,
+		InsecureSkipVerify: t.insecureSkipVerify,
+		MinVersion:         t.minVersion,
+	}
+	if unify4g.IsNotEmpty(t.caFile) {
+		pem, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the redis TLS CA bundle '%s': %v", t.caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("the redis TLS CA bundle '%s' contains no valid PEM certificate", t.caFile)
+		}
+		conf.RootCAs = pool
+	}
+	if unify4g.IsNotEmpty(t.certFile) || unify4g.IsNotEmpty(t.keyFile) {
+		cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the redis TLS client certificate '%s': %v", t.certFile, err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
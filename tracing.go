@@ -0,0 +1,138 @@
+package redisc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+)
+
+// spanKey is the context key under which a TracingHook carries its span from a Before to an After method.
+type spanKey struct{}
+
+// NewTracingHook creates a TracingHook starting its spans with the given tracer. Register it on a
+// Datasource with AddHook. Every span carries:
+//   - db.system: "redis";
+//   - db.statement: the command, rendered by the redactor (RedactArgs by default);
+//   - db.operation: the command name, or "pipeline";
+//   - db.redis.database_index: the database selected by the connection;
+//   - server.address and server.port: the server which processed the command (server.address holds the
+//     socket path for unix), i.e. the current master in sentinel mode and the node serving the command in
+//     cluster mode. They are recorded when the span ends, and not at all for the pipelines of a cluster,
+//     whose commands may be served by several nodes.
+//
+// The spans are children of the span carried by the context of the operation, e.g. the one given to
+// Cache.GetContext. The commands sent directly through the clients returned by Conn, ClusterConn and
+// Client, whose go-redis v6 API does not take a context, start root spans instead.
+func NewTracingHook(tracer Tracer) *TracingHook {
+	return &TracingHook{
+		tracer:   tracer,
+		redactor: RedactArgs,
+	}
+}
+
+// BeforeProcess starts the span of the command.
+func (t *TracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, span := t.start(ctx, cmd.Name())
+	span.SetAttribute("db.statement", t.redactor(cmd.Args()))
+	return context.WithValue(ctx, spanKey{}, span), nil
+}
+
+// AfterProcess records the error of the command, if any, and ends its span.
+func (t *TracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	t.end(ctx, cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline starts the span of the pipeline, whose statement lists the commands one per line.
+func (t *TracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, span := t.start(ctx, "pipeline")
+	statements := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		statements[i] = t.redactor(cmd.Args())
+	}
+	span.SetAttribute("db.statement", strings.Join(statements, "\n"))
+	span.SetAttribute("db.redis.pipeline_length", len(cmds))
+	return context.WithValue(ctx, spanKey{}, span), nil
+}
+
+// AfterProcessPipeline records the first error of the pipeline commands, if any, and ends its span.
+func (t *TracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if e := cmd.Err(); e != nil && e != redis.Nil {
+			err = e
+			break
+		}
+	}
+	t.end(ctx, err)
+	return nil
+}
+
+// start starts a span named after the operation and records the attributes describing the database.
+func (t *TracingHook) start(ctx context.Context, operation string) (context.Context, Span) {
+	r, _ := routeOf(ctx)
+	ctx, span := t.tracer.Start(ctx, "redis."+operation)
+	span.SetAttribute("db.system", "redis")
+	span.SetAttribute("db.operation", operation)
+	if r != nil {
+		span.SetAttribute("db.redis.database_index", r.database)
+	}
+	return ctx, span
+}
+
+// end records the server which processed the command and the given error, unless it is nil or redis.Nil,
+// then ends the span carried by ctx.
+func (t *TracingHook) end(ctx context.Context, err error) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	if r, ok := routeOf(ctx); ok && r.addr != "" {
+		if r.network == "unix" {
+			span.SetAttribute("server.address", r.addr)
+		} else if host, port, err := net.SplitHostPort(r.addr); err == nil {
+			span.SetAttribute("server.address", host)
+			if n, err := strconv.Atoi(port); err == nil {
+				span.SetAttribute("server.port", n)
+			}
+		}
+	}
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// RedactArgs renders the command name followed by a "?" placeholder per argument, e.g. "set ? ?".
+// It is the default Redactor, as keys and values may hold sensitive data.
+func RedactArgs(args []interface{}) string {
+	return redact(args, 1)
+}
+
+// RedactValues renders the command name and its first argument, usually the key, followed by a "?"
+// placeholder per remaining argument, e.g. "set user:42 ?".
+func RedactValues(args []interface{}) string {
+	return redact(args, 2)
+}
+
+// RedactNone renders the command name and every argument as is, e.g. "set user:42 secret".
+func RedactNone(args []interface{}) string {
+	return redact(args, len(args))
+}
+
+// redact renders the first n arguments as is and a "?" placeholder for every other argument.
+func redact(args []interface{}, n int) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if i < n {
+			parts[i] = fmt.Sprint(arg)
+		} else {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,200 @@
+package redisc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/go-redis/redis"
+)
+
+// fakeSpanKey is the context key under which the fakeTracer carries its current span.
+type fakeSpanKey struct{}
+
+// fakeTracer records the spans it starts in memory.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+// fakeSpan is a span recorded by the fakeTracer.
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+	span := &fakeSpan{name: name, parent: parent, attrs: make(map[string]interface{})}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+// span returns the first span started with the given name, or nil.
+func (t *fakeTracer) span(name string) *fakeSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *fakeSpan) RecordError(err error)                      { s.errs = append(s.errs, err) }
+func (s *fakeSpan) End()                                       { s.ended = true }
+
+// newTracedClient returns a Datasource traced by a fakeTracer and a client it instruments, which fails
+// every command as nothing listens on its address.
+func newTracedClient(ops *redis.Options) (*Datasource, *fakeTracer, *redis.Client) {
+	tracer := &fakeTracer{}
+	d := NewClient(*NewSettings())
+	d.AddHook(NewTracingHook(tracer).SetRedactor(RedactValues))
+	client := redis.NewClient(ops)
+	d.instrument(client)
+	return d, tracer, client
+}
+
+func TestTracingHookCommand(t *testing.T) {
+	d, tracer, client := newTracedClient(&redis.Options{Addr: "127.0.0.1:1", DB: 3})
+	defer client.Close()
+	ctx, parent := tracer.Start(context.Background(), "operation")
+	bound := d.bind(ctx, client)
+	if err := await(ctx, func() error { return bound.Set("user:42", "secret", 0).Err() }); err == nil {
+		t.Fatal("Set() error = nil, want a connection error")
+	}
+
+	span := tracer.span("redis.set")
+	if span == nil {
+		t.Fatal("no span started for the command")
+	}
+	if span.parent != parent {
+		t.Errorf("span parent = %v, want the span of the operation", span.parent)
+	}
+	want := map[string]interface{}{
+		"db.system":               "redis",
+		"db.operation":            "set",
+		"db.statement":            "set user:42 ?",
+		"db.redis.database_index": 3,
+		"server.address":          "127.0.0.1",
+		"server.port":             1,
+	}
+	for key, value := range want {
+		if got := span.attrs[key]; got != value {
+			t.Errorf("attribute %s = %v, want %v", key, got, value)
+		}
+	}
+	if len(span.errs) != 1 {
+		t.Errorf("recorded errors = %v, want the connection error", span.errs)
+	}
+	if !span.ended {
+		t.Error("span not ended")
+	}
+}
+
+func TestTracingHookPipeline(t *testing.T) {
+	_, tracer, client := newTracedClient(&redis.Options{Network: "unix", Addr: "/nonexistent/redis.sock"})
+	defer client.Close()
+	client.Pipelined(func(pipe redis.Pipeliner) error {
+		pipe.Get("a")
+		pipe.Del("b", "c")
+		return nil
+	})
+
+	span := tracer.span("redis.pipeline")
+	if span == nil {
+		t.Fatal("no span started for the pipeline")
+	}
+	if span.parent != nil {
+		t.Errorf("span parent = %v, want a root span for an unbound client", span.parent)
+	}
+	want := map[string]interface{}{
+		"db.operation":             "pipeline",
+		"db.statement":             "get a\ndel b ?",
+		"db.redis.pipeline_length": 2,
+		"server.address":           "/nonexistent/redis.sock",
+		"db.redis.database_index":  0,
+	}
+	for key, value := range want {
+		if got := span.attrs[key]; got != value {
+			t.Errorf("attribute %s = %v, want %v", key, got, value)
+		}
+	}
+	if _, ok := span.attrs["server.port"]; ok {
+		t.Error("server.port recorded for a unix socket")
+	}
+	if len(span.errs) != 1 || !span.ended {
+		t.Errorf("recorded errors = %v, ended = %v, want one error and an ended span", span.errs, span.ended)
+	}
+}
+
+func TestTracingHookErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"missing key", redis.Nil, 0},
+		{"failure", errors.New("READONLY"), 1},
+	}
+	for _, tt := range tests {
+		tracer := &fakeTracer{}
+		hook := NewTracingHook(tracer)
+		cmd := redis.NewStringResult("", tt.err)
+		ctx := context.WithValue(context.Background(), routeKey{}, &route{network: "tcp"})
+		ctx, _ = hook.BeforeProcess(ctx, cmd)
+		hook.AfterProcess(ctx, cmd)
+		span := tracer.span("redis.")
+		if span == nil {
+			t.Fatalf("%s: no span started", tt.name)
+		}
+		if len(span.errs) != tt.want {
+			t.Errorf("%s: recorded errors = %v, want %d", tt.name, span.errs, tt.want)
+		}
+		if _, ok := span.attrs["server.address"]; ok {
+			t.Errorf("%s: server.address recorded for an unknown route", tt.name)
+		}
+	}
+}
+
+func TestInstrumentNodeRecordsRoute(t *testing.T) {
+	d := NewClient(*NewSettings())
+	node := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer node.Close()
+	d.instrumentNode(node)
+	cmd := redis.NewStringCmd("get", "a")
+	r := &route{network: "tcp"}
+	d.routes.Store(cmd, r)
+	defer d.routes.Delete(cmd)
+	node.Process(cmd)
+	if r.addr != "127.0.0.1:1" {
+		t.Errorf("route address = %q, want the address of the node", r.addr)
+	}
+}
+
+func TestRedactors(t *testing.T) {
+	args := []interface{}{"set", "user:42", "secret", "ex", 60}
+	tests := []struct {
+		name     string
+		redactor Redactor
+		want     string
+	}{
+		{"args", RedactArgs, "set ? ? ? ?"},
+		{"values", RedactValues, "set user:42 ? ? ?"},
+		{"none", RedactNone, "set user:42 secret ex 60"},
+	}
+	for _, tt := range tests {
+		if got := tt.redactor(args); got != tt.want {
+			t.Errorf("%s: redacted = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
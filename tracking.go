@@ -0,0 +1,337 @@
+package redisc
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// OnInvalidate registers a handler receiving the keys invalidated by the server when tracking is enabled
+// (see Settings.IsTracking). The keys are the full redis keys; nil keys mean that every key must be
+// considered invalid, which happens on FLUSHALL or FLUSHDB, and whenever the tracking session is lost or
+// re-established, since the invalidation messages published in the meantime are missed.
+//
+// Returns a function removing the handler.
+func (d *Datasource) OnInvalidate(handler func(keys []string)) func() {
+	t := &d.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.handlers == nil {
+		t.handlers = make(map[int]func(keys []string))
+	}
+	t.next++
+	id := t.next
+	t.handlers[id] = handler
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.handlers, id)
+	}
+}
+
+// TrackedClient returns the client whose reads are tracked by the server, in a thread-safe manner.
+// In TrackingDefault mode, only the keys read through this client are invalidated; in TrackingBroadcast
+// mode, it is the client of the Datasource.
+//
+// Returns:
+//   - the tracked client if a tracking session is established;
+//   - nil otherwise, in which case the values read must not be cached in-process.
+func (d *Datasource) TrackedClient() redis.UniversalClient {
+	t := &d.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	if t.reader != nil {
+		return t.reader
+	}
+	return d.Client()
+}
+
+// IsTracking returns true if a tracking session is established, i.e. the server pushes the invalidation
+// of the tracked keys.
+func (d *Datasource) IsTracking() bool {
+	t := &d.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn != nil
+}
+
+// track establishes a new tracking session against the server described by ops, replacing the current
+// one. A dedicated connection is subscribed to the invalidation channel; in TrackingDefault mode, a
+// client whose connections redirect their invalidation messages to it is created for the tracked reads,
+// and in TrackingBroadcast mode, the connection tracks the configured prefixes itself. Every handler
+// registered by OnInvalidate is flushed, since the keys tracked by the previous session are not anymore.
+//
+// Returns:
+//   - nil if the session has been established;
+//   - an error if the connection could not be established or the server rejected CLIENT TRACKING.
+func (d *Datasource) track(ops *redis.Options) error {
+	t := &d.tracker
+	t.mu.Lock()
+	t.ops = ops
+	t.mu.Unlock()
+
+	conn, r, err := dialTracking(ops)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(conn)
+	id, err := handshakeTracking(conn, r, w, ops, d.conf.tracking)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	var reader *redis.Client
+	if d.conf.tracking.mode != TrackingBroadcast {
+		readerOps := *ops
+		readerOps.OnConnect = func(cn *redis.Conn) error {
+			cmd := redis.NewStatusCmd("client", "tracking", "on", "redirect", strconv.FormatInt(id, 10))
+			cn.Process(cmd)
+			return cmd.Err()
+		}
+		reader = redis.NewClient(&readerOps)
+		d.instrument(reader)
+	}
+
+	t.mu.Lock()
+	if d.IsClosed() {
+		t.mu.Unlock()
+		conn.Close()
+		if reader != nil {
+			reader.Close()
+		}
+		return errDatasourceClosed
+	}
+	previous, previousReader := t.conn, t.reader
+	t.conn, t.id, t.reader = conn, id, reader
+	supervise := !d.conf.keepalive && !t.supervised
+	t.supervised = t.supervised || supervise
+	t.mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	if previousReader != nil {
+		previousReader.Close()
+	}
+	d.invalidate(nil)
+	go d.invalidations(conn, r)
+	if supervise {
+		d.superviseTracking()
+	}
+	d.log(levelInfo, "The tracking session has been established", "tracking_mode", string(d.conf.tracking.mode), "tracking_client_id", id)
+	return nil
+}
+
+// untrack closes the current tracking session, if any, and flushes every handler registered by OnInvalidate.
+func (d *Datasource) untrack() {
+	d.loseTracking(nil, nil)
+}
+
+// loseTracking closes the tracking session established on conn, or the current one if conn is nil, and
+// flushes every handler registered by OnInvalidate. It has no effect if the session has already been replaced.
+func (d *Datasource) loseTracking(conn net.Conn, err error) {
+	t := &d.tracker
+	t.mu.Lock()
+	if t.conn == nil || (conn != nil && t.conn != conn) {
+		t.mu.Unlock()
+		return
+	}
+	current, reader := t.conn, t.reader
+	t.conn, t.id, t.reader = nil, 0, nil
+	t.mu.Unlock()
+	current.Close()
+	if reader != nil {
+		reader.Close()
+	}
+	d.invalidate(nil)
+	if err != nil {
+		d.log(levelWarn, "The tracking session has been lost, every in-process entry is flushed", "error", err)
+	}
+}
+
+// keepTracking checks the tracking session on every keepalive tick: a PING is sent on the invalidation
+// connection, whose reply the invalidation routine must read in time, and a lost session is established again.
+func (d *Datasource) keepTracking() {
+	t := &d.tracker
+	t.mu.Lock()
+	conn, ops := t.conn, t.ops
+	t.mu.Unlock()
+	if conn == nil {
+		if ops == nil {
+			return
+		}
+		if err := d.track(ops); err != nil {
+			d.log(levelWarn, "The tracking session could not be established", "error", err)
+		}
+		return
+	}
+	t.writing.Lock()
+	var deadline time.Time
+	if timeout := d.conf.timeout.writeTimeout; timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	conn.SetWriteDeadline(deadline)
+	err := writeCommand(bufio.NewWriter(conn), "PING")
+	t.writing.Unlock()
+	if err != nil {
+		d.loseTracking(conn, err)
+	}
+}
+
+// superviseTracking runs keepTracking on every ping interval until the Datasource is closed, in place of
+// the keepalive routine when it is disabled, so that a half-open invalidation connection is still detected
+// and the session established again.
+func (d *Datasource) superviseTracking() {
+	d.routines.Add(1)
+	go func() {
+		defer d.routines.Done()
+		ticker := time.NewTicker(d.trackingInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+				d.keepTracking()
+			}
+		}
+	}()
+}
+
+// trackingInterval returns the interval at which the invalidation connection is pinged, which is the
+// ping interval of the Datasource.
+func (d *Datasource) trackingInterval() time.Duration {
+	if interval := d.conf.PingInterval(); interval > 0 {
+		return interval
+	}
+	return defaultPingInterval
+}
+
+// invalidations reads the messages received on the invalidation connection and dispatches the invalidated
+// keys to the handlers registered by OnInvalidate, until the connection is closed or stops answering.
+func (d *Datasource) invalidations(conn net.Conn, r *bufio.Reader) {
+	// keepTracking sends a PING on every ping interval, so a silent connection is a dead one.
+	timeout := 3 * d.trackingInterval()
+	for {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		reply, err := readReply(r)
+		if err != nil {
+			d.loseTracking(conn, err)
+			return
+		}
+		items, ok := reply.([]interface{})
+		if !ok || len(items) != 3 || items[0] != "message" || items[1] != trackingChannel {
+			continue
+		}
+		switch payload := items[2].(type) {
+		case nil:
+			d.invalidate(nil)
+		case string:
+			d.invalidate([]string{payload})
+		case []interface{}:
+			keys := make([]string, 0, len(payload))
+			for _, key := range payload {
+				if key, ok := key.(string); ok {
+					keys = append(keys, key)
+				}
+			}
+			d.invalidate(keys)
+		}
+	}
+}
+
+// invalidate calls every handler registered by OnInvalidate with the given keys, nil meaning every key.
+func (d *Datasource) invalidate(keys []string) {
+	t := &d.tracker
+	t.mu.Lock()
+	handlers := make([]func(keys []string), 0, len(t.handlers))
+	for _, handler := range t.handlers {
+		handlers = append(handlers, handler)
+	}
+	t.mu.Unlock()
+	for _, handler := range handlers {
+		handler(keys)
+	}
+}
+
+// dialTracking opens the invalidation connection with the dialer of ops, encrypted with TLS if configured.
+func dialTracking(ops *redis.Options) (net.Conn, *bufio.Reader, error) {
+	var conn net.Conn
+	var err error
+	if ops.Dialer != nil {
+		conn, err = ops.Dialer()
+	} else {
+		conn, err = net.DialTimeout(ops.Network, ops.Addr, ops.DialTimeout)
+		if err == nil && ops.TLSConfig != nil {
+			conn = tls.Client(conn, ops.TLSConfig)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, bufio.NewReader(conn), nil
+}
+
+// handshakeTracking authenticates the invalidation connection, enables broadcast tracking on it if
+// configured, and subscribes it to the invalidation channel.
+//
+// Returns:
+//   - the identifier of the connection;
+//   - an error if any command is rejected or the connection fails.
+func handshakeTracking(conn net.Conn, r *bufio.Reader, w *bufio.Writer, ops *redis.Options, conf *trackingSettings) (int64, error) {
+	if ops.DialTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(ops.DialTimeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+	call := func(args ...string) (interface{}, error) {
+		if err := writeCommand(w, args...); err != nil {
+			return nil, err
+		}
+		reply, err := readReply(r)
+		if err != nil {
+			return nil, err
+		}
+		if e, ok := reply.(respError); ok {
+			// The arguments of AUTH are not reported, as they hold the password.
+			name := args[0]
+			if name == "CLIENT" {
+				name += " " + args[1]
+			}
+			return nil, fmt.Errorf("%s: %v", name, e)
+		}
+		return reply, nil
+	}
+	if ops.Password != "" {
+		if _, err := call("AUTH", ops.Password); err != nil {
+			return 0, err
+		}
+	}
+	reply, err := call("CLIENT", "ID")
+	if err != nil {
+		return 0, err
+	}
+	id, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("CLIENT ID returned an unexpected reply: %v", reply)
+	}
+	if conf.mode == TrackingBroadcast {
+		args := []string{"CLIENT", "TRACKING", "ON", "REDIRECT", strconv.FormatInt(id, 10), "BCAST"}
+		for _, prefix := range conf.prefixes {
+			args = append(args, "PREFIX", prefix)
+		}
+		if _, err := call(args...); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := call("SUBSCRIBE", trackingChannel); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
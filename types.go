@@ -1,6 +1,9 @@
 package redisc
 
 import (
+	"context"
+	"log/slog"
+	"net"
 	"sync"
 	"time"
 
@@ -22,6 +25,26 @@ type Settings struct {
 	// to reconnect if the connection is lost.
 	keepalive bool
 
+	// Indicates whether the connection events are only published when the connection state
+	// changes (e.g. from Connected to Degraded), rather than on every keepalive ping.
+	transitionsOnly bool
+
+	// Indicates whether the initial connection is established in the background. When set to true,
+	// NewClient returns immediately and keeps connecting under the reconnect policy; use WaitReady
+	// to block until the connection is ready.
+	lazy bool
+
+	// Defines the duration above which a command is considered slow, logged and retained in the
+	// slow log of the Datasource. A zero value disables the detection of slow commands.
+	slowThreshold time.Duration
+
+	// Defines the number of slow commands retained in the slow log of the Datasource.
+	slowLogSize int
+
+	// The logger receiving the log lines of the Datasource; a loggy adapter when nil.
+	// It is not (un)marshalled.
+	logger Logger
+
 	conn *connectionSettings
 
 	retry *retrySettings
@@ -29,6 +52,16 @@ type Settings struct {
 	timeout *timeoutSettings
 
 	pool *poolSettings
+
+	sentinel *sentinelSettings
+
+	cluster *clusterSettings
+
+	tls *tlsSettings
+
+	reconnect *reconnectSettings
+
+	tracking *trackingSettings
 }
 
 type connectionSettings struct {
@@ -119,6 +152,120 @@ type poolSettings struct {
 	idleCheckFrequency time.Duration
 }
 
+type sentinelSettings struct {
+	// Indicates whether the connection is resolved through Redis Sentinel.
+	// When set to true, the master address is discovered from the sentinels instead of
+	// being read from connectionSettings.connectionStrings.
+	enabled bool
+
+	// The name of the master group monitored by the sentinels (e.g. "mymaster").
+	// Required when sentinel mode is enabled.
+	masterName string
+
+	// The list of sentinel addresses in "host:port" format.
+	// Sentinels are queried in order until one of them reports the current master.
+	addrs []string
+
+	// The password for authentication with the sentinel nodes.
+	// Leave empty if the sentinels are not protected by a password.
+	password string
+}
+
+type clusterSettings struct {
+	// Indicates whether the connection targets a sharded Redis Cluster.
+	// When set to true, a cluster-aware client is created from the seed nodes instead of
+	// a single-node client built from connectionSettings.connectionStrings.
+	enabled bool
+
+	// A seed list of cluster node addresses in "host:port" format.
+	// The full cluster topology is discovered from any reachable seed node.
+	addrs []string
+
+	// Enables read-only commands on replica nodes.
+	// Use it to offload reads from the masters when slightly stale data is acceptable.
+	readOnly bool
+
+	// Routes read-only commands to the closest master or replica node.
+	// It automatically enables readOnly.
+	routeByLatency bool
+
+	// Routes read-only commands to a random master or replica node.
+	// It automatically enables readOnly.
+	routeRandomly bool
+
+	// The maximum number of MOVED/ASK redirects to follow before giving up on a command.
+	// Default is 8 redirects; -1 disables redirects.
+	maxRedirects int
+}
+
+type tlsSettings struct {
+	// Indicates whether the connection to the Redis server is encrypted with TLS.
+	// Required by most managed Redis offerings; the connection URL uses the "rediss://" scheme.
+	enabled bool
+
+	// The path to a PEM-encoded CA bundle used to verify the server certificate.
+	// Leave empty to use the system certificate pool.
+	caFile string
+
+	// The path to a PEM-encoded client certificate, used for mutual TLS.
+	// Must be set together with keyFile.
+	certFile string
+
+	// The path to the PEM-encoded private key of the client certificate.
+	// Must be set together with certFile.
+	keyFile string
+
+	// The server name used to verify the hostname on the server certificate.
+	// Leave empty to use the host of the connection address.
+	serverName string
+
+	// Disables the verification of the server certificate chain and host name.
+	// Only use it in development environments; it makes the connection vulnerable to man-in-the-middle attacks.
+	insecureSkipVerify bool
+
+	// The minimum TLS version accepted (e.g. tls.VersionTLS12).
+	// Default is TLS 1.2.
+	minVersion uint16
+}
+
+type reconnectSettings struct {
+	// The delay before the second reconnection attempt; the first attempt is made immediately
+	// after a failed ping. Subsequent delays grow by multiplier.
+	initialDelay time.Duration
+
+	// The factor applied to the delay after each failed attempt (e.g. 2 doubles the delay).
+	// Use 1 for a constant delay.
+	multiplier float64
+
+	// The upper bound of the delay between two attempts, regardless of the multiplier.
+	// Prevents the delay from growing excessively during long outages.
+	maxDelay time.Duration
+
+	// The fraction of the delay randomly added or subtracted (e.g. 0.2 for ±20%).
+	// Spreads the reconnections of many instances to avoid overloading a recovering server.
+	jitter float64
+
+	// The maximum number of consecutive failed attempts before the policy gives up (0 means unlimited).
+	maxAttempts int
+
+	// The action taken when the policy gives up after maxAttempts failed attempts.
+	giveUp GiveUpAction
+}
+
+type trackingSettings struct {
+	// Enables server-assisted client-side caching (CLIENT TRACKING, redis 6 or later), through which
+	// the server pushes the invalidation of the keys cached in-process, e.g. by a NearCache.
+	// Not supported in cluster mode.
+	enabled bool
+
+	// The tracking mode: TrackingDefault tracks the keys read by the instance, TrackingBroadcast
+	// tracks every key matching prefixes, regardless of whether it has been read.
+	mode TrackingMode
+
+	// The key prefixes tracked in TrackingBroadcast mode (every key if empty).
+	prefixes []string
+}
+
 type Datasource struct {
 	// A read-write mutex that ensures safe concurrent access to the Datasource fields.
 	mu sync.RWMutex
@@ -128,18 +275,526 @@ type Datasource struct {
 	wrap wrapify.R
 	// A pointer to an redis.Client object representing the active connection to the Redis database.
 	conn *redis.Client
-	// A callback function that is invoked asynchronously when there is a change in connection status,
-	//  such as when the connection is lost, re-established, or its health is updated.
-	on func(response wrapify.R)
-	// onReplica is a callback function that is invoked asynchronously to handle events related to replica connections.
-	// When the status of a replica datasource changes (e.g., during failover, reconnection, or health updates),
-	// this function is triggered with the current status (encapsulated in wrapify.R) and a pointer to the Datasource
-	// representing the replica connection. This allows external components to implement replica-specific logic
-	// for tasks such as load balancing, monitoring, or failover handling independently of the primary connection.
-	onReplica func(response wrapify.R, replicator *Datasource)
-	// notifier is an optional callback function used to propagate notifications for significant datasource events.
-	// It is invoked with the current status (encapsulated in wrapify.R) whenever notable events occur,
-	// such as reconnection attempts, keepalive signals, or other diagnostic updates.
-	// This allows external components to receive and handle these notifications independently of the primary connection status callback.
-	notifier func(response wrapify.R)
+	// A pointer to an redis.ClusterClient object representing the active connection to the Redis Cluster.
+	// It is only set when cluster mode is enabled, in which case conn remains nil.
+	cluster *redis.ClusterClient
+	// The address of the redis master currently in use. In sentinel mode, it is refreshed on every
+	// keepalive tick so that master promotions can be detected and followed. In cluster mode, it holds
+	// the comma-separated seed node addresses.
+	master string
+	// The round-trip time of the last successful ping, used to rank replicas by latency.
+	latency time.Duration
+	// The primary Datasource this Datasource replicates, set when it belongs to a ReplicaSet.
+	// Replica status changes are reported through the onReplica callback of the primary.
+	primary *Datasource
+	// The event bus delivering the typed events of the Datasource to its subscribers.
+	events eventBus
+	// The subscription registered by SetOn, which receives the connection status changes,
+	// such as when the connection is lost, re-established, or its health is updated.
+	on *Subscription
+	// The subscription registered by SetOnReplica, which receives the status changes of the replicas
+	// of a ReplicaSet (e.g., during failover, reconnection, or health updates) along with the replica
+	// Datasource. This allows external components to implement replica-specific logic for tasks such as
+	// load balancing, monitoring, or failover handling independently of the primary connection.
+	onReplica *Subscription
+	// The subscription registered by SetNotifier, which receives the significant datasource events,
+	// such as command errors or a reconnection policy giving up, independently of the connection status.
+	notifier *Subscription
+	// The number of consecutive failed reconnection attempts, reset on success.
+	attempts int
+	// stopped indicates that the reconnection policy gave up with GiveUpStop, which stopped the keepalive
+	// routine until a manual reconnection succeeds.
+	stopped bool
+	// The current state of the connection, updated on every status change.
+	state ConnState
+	// The most recent state transitions, oldest first, bounded by defaultStateHistorySize.
+	history []StateTransition
+	// closed indicates that Close or Shutdown has been called; every subsequent operation is rejected.
+	closed bool
+	// done is closed by Shutdown to signal the keepalive routine to stop.
+	done chan struct{}
+	// ready is closed once the first connection succeeds, or once the Datasource stops trying to connect.
+	ready chan struct{}
+	// The error reported by WaitReady once ready is closed; nil if the connection succeeded.
+	readyErr error
+	// The metrics recorded for the Datasource and rendered by a Collector.
+	metrics metrics
+	// The hooks invoked around every command and pipeline, in registration order.
+	hooks []Hook
+	// The ring buffer of the most recent slow commands.
+	slowLog slowLog
+	// The calls of the commands sent through the clients returned by bind, keyed by command, which
+	// provide the context of the hooks and the caller recorded in the slow log.
+	calls sync.Map
+	// The servers processing the commands of the cluster connection, keyed by command, whose address
+	// is recorded by the cluster node serving the command.
+	routes sync.Map
+	// The logger set by SetLogger, which takes precedence over the logger of the Settings.
+	logger Logger
+	// routines tracks the keepalive routine so that Shutdown can wait for it to stop.
+	routines sync.WaitGroup
+	// callbacks tracks the delivery routines of the subscriptions so that Shutdown can drain them.
+	callbacks sync.WaitGroup
+	// The dedicated connection receiving the invalidation messages of CLIENT TRACKING.
+	tracker tracker
+}
+
+// TrackingMode defines how the keys cached in-process are tracked by the server, see CLIENT TRACKING.
+type TrackingMode string
+
+// tracker holds the session of server-assisted client-side caching of a Datasource: the invalidation
+// connection, and in TrackingDefault mode the client whose reads are tracked. A new session is
+// established on every reconnection.
+type tracker struct {
+	mu sync.Mutex
+	// The options of the current connection, used to establish the session again once lost.
+	ops *redis.Options
+	// The dedicated connection subscribed to the invalidation channel, nil if no session is established.
+	conn net.Conn
+	// The identifier of conn, which the tracked connections redirect their invalidation messages to.
+	id int64
+	// The client whose connections are tracked, redirecting to conn; nil in TrackingBroadcast mode.
+	reader *redis.Client
+	// Serializes the writes to conn.
+	writing sync.Mutex
+	// Whether the routine pinging conn in place of the keepalive routine, which is disabled, is started.
+	supervised bool
+	// The handlers registered by OnInvalidate, by registration number.
+	handlers map[int]func(keys []string)
+	next     int
+}
+
+// GiveUpAction defines what a Datasource does when its reconnection policy gives up.
+type GiveUpAction string
+
+// ConnState describes the state of the connection held by a Datasource.
+type ConnState int
+
+// StateTransition records a change of the connection state of a Datasource.
+type StateTransition struct {
+	// The state before the transition.
+	From ConnState `json:"from"`
+	// The state after the transition.
+	To ConnState `json:"to"`
+	// The time at which the transition occurred.
+	At time.Time `json:"at"`
+	// The message of the response that caused the transition.
+	Reason string `json:"reason"`
+}
+
+// Codec converts the values of a Cache to and from the bytes stored in redis.
+type Codec interface {
+	// Marshal encodes the value v.
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is a Codec encoding the values as JSON.
+type JSONCodec struct{}
+
+// GobCodec is a Codec encoding the values with encoding/gob.
+type GobCodec struct{}
+
+// RawCodec is a Codec storing []byte and string values as is.
+type RawCodec struct{}
+
+// Cache is a typed view over the keys of a Datasource, whose values are converted with a Codec.
+type Cache[T any] struct {
+	// The Datasource the values are stored in.
+	datasource *Datasource
+	// The codec converting the values to and from bytes.
+	codec Codec
+	// The prefix prepended to every key, e.g. "users:".
+	prefix string
+	// The duration an expired value is still served by GetOrLoad while it is refreshed, 0 to disable.
+	staleTTL time.Duration
+	// The expiration of the lock taken by GetOrLoad before loading a value, 0 to disable.
+	lockTTL time.Duration
+	// The loads of GetOrLoad in progress, coalescing the concurrent misses of a key.
+	flights *flightGroup[T]
+}
+
+// EvictionPolicy selects the entry a NearCache evicts when it is full.
+type EvictionPolicy string
+
+// NearCache is a two-level cache: a bounded in-process cache in front of a Cache, written through to
+// redis and kept consistent across instances by invalidation messages broadcast over pub/sub.
+type NearCache[T any] struct {
+	// The Cache the values are read from and written through to.
+	cache *Cache[T]
+	// The in-process entries, bounded in size and age.
+	local *localCache[T]
+	// The pub/sub channel the invalidation messages are broadcast on.
+	channel string
+	// The random identifier of this instance, used to ignore its own invalidation messages.
+	id string
+	// Closed by Close to stop the invalidation routine.
+	done chan struct{}
+	// Guards done against being closed twice.
+	once sync.Once
+	// Whether the invalidation messages are pushed by the server (see Settings.IsTracking) rather than
+	// broadcast over pub/sub.
+	tracked bool
+	// Removes the handler registered with OnInvalidate when tracked.
+	untrack func()
+}
+
+// invalidation is the message broadcast by a NearCache when it writes or deletes keys.
+type invalidation struct {
+	ID   string   `json:"id"`
+	Keys []string `json:"keys"`
+}
+
+// localCache is a bounded, expiring in-process cache, ordered by its eviction policy.
+type localCache[T any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	policy  EvictionPolicy
+	entries map[string]*localEntry[T]
+	// The entries ordered by eviction priority, see container/heap.
+	queue localQueue[T]
+	// A counter incremented on every access, ordering the entries by recency.
+	clock uint64
+	// The number of hits since the hit counts were last halved, used by EvictLFU.
+	hits int
+	// The reads from redis in progress, by key, so that a value read before an invalidation of its key
+	// is not stored once the invalidation has been received.
+	reads map[string]*localRead
+	// A counter incremented on every purge, so that the values read before a purge are not stored.
+	epoch uint64
+}
+
+// localRead tracks the reads of a key from redis in progress.
+type localRead struct {
+	// The number of reads in progress.
+	readers int
+	// A counter incremented on every invalidation of the key.
+	generation uint64
+}
+
+// localTicket identifies a read of a key from redis, started with acquire.
+type localTicket struct {
+	key        string
+	generation uint64
+	epoch      uint64
+}
+
+// localEntry is an entry of a localCache.
+type localEntry[T any] struct {
+	key       string
+	value     T
+	expiresAt time.Time
+	// The number of hits, used by EvictLFU.
+	hits uint64
+	// The value of the clock on the last access, used by EvictLRU and to break ties of EvictLFU.
+	used uint64
+	// The position of the entry in the queue.
+	index int
+}
+
+// localQueue is a heap of entries, whose head is the next entry to evict.
+type localQueue[T any] struct {
+	entries []*localEntry[T]
+	policy  EvictionPolicy
+}
+
+// Loader computes the value of a key missing from a Cache, see GetOrLoad.
+type Loader[T any] func(ctx context.Context, key string) (T, error)
+
+// flightGroup coalesces the concurrent calls made for the same key into a single one.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+// flightCall is a call in progress, or completed, in a flightGroup.
+type flightCall[T any] struct {
+	// Closed once the call has completed.
+	done     chan struct{}
+	value    T
+	response wrapify.R
+}
+
+// Health describes the health of a Datasource, as served by its HealthHandler.
+type Health struct {
+	// The current connection state, e.g. "connected".
+	State ConnState `json:"state"`
+	// Whether the Datasource is connected.
+	Connected bool `json:"connected"`
+	// Whether the Datasource is ready to serve commands: connected, with a connection pool that is not exhausted.
+	Ready bool `json:"ready"`
+	// The status code of the current connection status (see Datasource.Wrap).
+	StatusCode int `json:"status_code"`
+	// The message of the current connection status.
+	Message string `json:"message"`
+	// The error of the current connection status, if any.
+	Error string `json:"error,omitempty"`
+	// The debugging values of the current connection status, e.g. redis_conn_str.
+	Debugging map[string]interface{} `json:"debugging,omitempty"`
+	// The address of the redis master currently in use.
+	Master string `json:"master,omitempty"`
+	// The round-trip time of the last successful ping, e.g. "1.2ms".
+	Latency string `json:"latency"`
+	// The number of consecutive failed reconnection attempts.
+	ReconnectAttempts int `json:"reconnect_attempts"`
+	// The statistics of the connection pool; nil if there is no connection.
+	Pool *PoolHealth `json:"pool,omitempty"`
+}
+
+// PoolHealth describes the connection pool of a Datasource.
+type PoolHealth struct {
+	// The maximum number of connections of the pool (per node in cluster mode).
+	Size int `json:"size"`
+	// The number of connections in the pool.
+	TotalConns uint32 `json:"total_conns"`
+	// The number of idle connections in the pool.
+	IdleConns uint32 `json:"idle_conns"`
+	// The number of stale connections removed from the pool.
+	StaleConns uint32 `json:"stale_conns"`
+	// The number of times a free connection was found in the pool.
+	Hits uint32 `json:"hits"`
+	// The number of times a free connection was not found in the pool.
+	Misses uint32 `json:"misses"`
+	// The number of times a wait for a connection timed out.
+	Timeouts uint32 `json:"timeouts"`
+	// Whether every connection of the pool is in use and no more connection can be opened.
+	// Always false in cluster mode, see Datasource.Health.
+	Exhausted bool `json:"exhausted"`
+}
+
+// Logger receives the log lines of a Datasource. The fields are alternating keys and values,
+// e.g. "redis_conn_str", "redis://localhost:6379", "executed_in", "1.2ms", as in log/slog.
+type Logger interface {
+	Debug(msg string, fields ...interface{})
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+	Error(msg string, fields ...interface{})
+}
+
+// logLevel identifies the severity of a log line.
+type logLevel int
+
+// loggyLogger is the default Logger, writing the log lines through the loggy package.
+type loggyLogger struct{}
+
+// slogLogger is a Logger writing the log lines through a log/slog Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// Hook intercepts the commands and pipelines processed by the connections of a Datasource, e.g. to
+// trace, log, audit or inject faults. The context returned by a Before method is passed to the
+// matching After method, so that a hook can carry state (such as a span) from one to the other.
+type Hook interface {
+	// BeforeProcess is called before a command is sent. If it returns an error, the command is not sent.
+	BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error)
+	// AfterProcess is called once a command has been processed, or skipped by a failing BeforeProcess.
+	AfterProcess(ctx context.Context, cmd redis.Cmder) error
+	// BeforeProcessPipeline is called before a pipeline or transaction is sent. If it returns an error,
+	// the pipeline is not sent.
+	BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error)
+	// AfterProcessPipeline is called once a pipeline has been processed, or skipped by a failing
+	// BeforeProcessPipeline.
+	AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error
+}
+
+// Tracer starts the spans recorded by a TracingHook. It is a small adapter over a tracing library
+// (e.g. OpenTelemetry), which also makes it easy to record the spans in memory.
+type Tracer interface {
+	// Start creates a span with the given name, child of the span carried by ctx if any, and returns
+	// the context carrying the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	// SetAttribute records an attribute of the span, e.g. db.system="redis".
+	SetAttribute(key string, value interface{})
+	// RecordError records an error and marks the span as failed.
+	RecordError(err error)
+	// End completes the span.
+	End()
+}
+
+// Redactor renders the db.statement attribute of a span from the arguments of a command,
+// the first argument being the command name.
+type Redactor func(args []interface{}) string
+
+// TracingHook is a Hook that records a span for every command and pipeline, following the
+// OpenTelemetry semantic conventions for database clients.
+type TracingHook struct {
+	// The tracer starting the spans.
+	tracer Tracer
+	// The function rendering the db.statement attribute; RedactArgs by default.
+	redactor Redactor
+}
+
+// call describes the operation a command is sent for, as captured by bind in the goroutine of the
+// caller before the command is handed over to another goroutine by await.
+type call struct {
+	// The context of the operation, handed to the hooks.
+	ctx context.Context
+	// The program counters of the stack of the caller, resolved into the slow log caller on demand.
+	callers []uintptr
+}
+
+// route describes the redis server processing a command, which the hooks read from their context.
+type route struct {
+	// The network of the server ("tcp" or "unix").
+	network string
+	// The address of the server, empty if unknown, e.g. for a pipeline spanning several cluster nodes.
+	addr string
+	// The database index selected on the server.
+	database int
+}
+
+// SlowLogSource identifies where a SlowLogEntry has been recorded.
+type SlowLogSource string
+
+// SlowLogEntry describes a slow command, as measured by the client or reported by the server's SLOWLOG.
+type SlowLogEntry struct {
+	// Where the entry has been recorded.
+	Source SlowLogSource `json:"source"`
+	// The identifier of the entry in the server's SLOWLOG; 0 for client entries.
+	ID int64 `json:"id,omitempty"`
+	// The time at which the command was executed.
+	Time time.Time `json:"time"`
+	// The command name, e.g. "get".
+	Command string `json:"command"`
+	// The first argument of the command, usually the key; empty if the command has no argument.
+	Key string `json:"key,omitempty"`
+	// The duration of the command: the round-trip time for client entries, the execution time for server entries.
+	Duration time.Duration `json:"duration"`
+	// The location ("file:line") of the code that issued the command, for client entries.
+	Caller string `json:"caller,omitempty"`
+	// The address of the server node that reported the entry, for server entries.
+	Server string `json:"server,omitempty"`
+}
+
+// slowLog is a ring buffer retaining the most recent slow commands. Its zero value is ready to use.
+type slowLog struct {
+	mu sync.Mutex
+	// The retained entries; once full, the oldest entry is at index next.
+	entries []SlowLogEntry
+	// The index at which the next entry is written once the buffer is full.
+	next int
+}
+
+// EventType identifies the kind of an Event published by a Datasource.
+type EventType int
+
+// Event describes a notable change observed by a Datasource, as delivered to its subscribers.
+type Event struct {
+	// The kind of the event.
+	Type EventType
+	// The connection state of the Datasource after the event.
+	State ConnState
+	// Whether the event changed the connection state.
+	Changed bool
+	// The response describing the event, as recorded by Wrap for connection events.
+	Response wrapify.R
+	// The replica the event originates from, for EventReplicaChanged; nil otherwise.
+	Replica *Datasource
+	// The time at which the event occurred.
+	At time.Time
+}
+
+// Subscription is a registration of a handler on the events of a Datasource. Events are queued in a
+// bounded buffer and delivered in order by a dedicated goroutine; when the buffer is full, the events
+// are dropped and counted rather than blocking the Datasource.
+type Subscription struct {
+	// The bus the subscription is registered on.
+	bus *eventBus
+	// The identifier of the subscription on the bus.
+	id uint64
+	// The event types delivered to the handler; every type is delivered when empty.
+	types map[EventType]bool
+	// The buffer of events waiting to be delivered.
+	queue chan Event
+	// The number of events dropped because the buffer was full, accessed atomically.
+	dropped uint64
+}
+
+// eventBus dispatches the events of a Datasource to its subscriptions. Its zero value is ready to use.
+type eventBus struct {
+	mu sync.Mutex
+	// The active subscriptions, by identifier.
+	subscriptions map[uint64]*Subscription
+	// The identifier assigned to the last subscription.
+	next uint64
+	// closed indicates that the bus no longer accepts subscriptions nor events.
+	closed bool
+	// The number of events dropped across every subscription, accessed atomically.
+	dropped uint64
+}
+
+// histogram accumulates observations into cumulative buckets, as in the Prometheus histogram type.
+type histogram struct {
+	// The upper bounds of the buckets, in seconds, in increasing order.
+	bounds []float64
+	// The number of observations falling into each bucket, not cumulated.
+	counts []uint64
+	// The sum of every observation, in seconds.
+	sum float64
+	// The number of observations.
+	count uint64
+}
+
+// commandMetrics holds the metrics recorded for a single command name.
+type commandMetrics struct {
+	// The number of executions of the command.
+	calls uint64
+	// The number of executions that failed, not counting redis.Nil replies.
+	errors uint64
+	// The duration of the executions of the command.
+	latency histogram
+}
+
+// metrics holds the metrics recorded by a Datasource. Its zero value is ready to use.
+type metrics struct {
+	mu sync.Mutex
+	// The round-trip time of the successful keepalive pings.
+	pings histogram
+	// The number of failed keepalive pings.
+	pingFailures uint64
+	// The number of successful reconnections.
+	reconnects uint64
+	// The number of failed reconnection attempts.
+	reconnectFailures uint64
+	// The metrics of the executed commands, by lower-cased command name.
+	commands map[string]*commandMetrics
+}
+
+// Collector renders the metrics of one or more Datasources in the Prometheus text exposition format.
+// It implements http.Handler, so it can be mounted on any mux to be scraped.
+type Collector struct {
+	mu sync.RWMutex
+	// The prefix of every metric name.
+	namespace string
+	// The registered Datasources, in registration order.
+	sources []collectorSource
+}
+
+// collectorSource is a Datasource registered on a Collector, along with the value of its "datasource" label.
+type collectorSource struct {
+	name       string
+	datasource *Datasource
+}
+
+// ReplicaStrategy defines how a ReplicaSet picks the replica that serves a read-only command.
+type ReplicaStrategy int
+
+type ReplicaSet struct {
+	// The primary Datasource that serves write commands, and read commands when no replica is healthy.
+	primary *Datasource
+	// The replica Datasources that serve read-only commands. Each replica runs its own keepalive loop.
+	replicas []*Datasource
+	// Guards strategy, which SetStrategy may change while commands are routed.
+	mu sync.RWMutex
+	// The strategy used to pick a healthy replica for read-only commands.
+	strategy ReplicaStrategy
+	// A monotonically increasing counter used by the round-robin strategy.
+	next uint64
 }
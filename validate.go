@@ -0,0 +1,144 @@
+package redisc
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/sivaosorg/unify4g"
+)
+
+// Validate checks every field of the Settings and its sub-settings and reports all the
+// problems found at once, rather than stopping at the first one. It is invoked by NewClient
+// for enabled Settings, so that a misconfiguration surfaces as a configuration error instead
+// of an opaque connection failure.
+//
+// Returns:
+//   - nil if the Settings are valid;
+//   - an error joining (see errors.Join) one error per problem found.
+func (c *Settings) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	check(c.pingInterval >= 0, "ping_interval must not be negative: %v", c.pingInterval)
+	check(c.slowThreshold >= 0, "slow_threshold must not be negative: %v", c.slowThreshold)
+	check(c.slowLogSize >= 0, "slow_log_size must not be negative: %d", c.slowLogSize)
+
+	if c.conn == nil {
+		errs = append(errs, errors.New("conn settings are required"))
+	} else {
+		check(c.conn.network == "tcp" || c.conn.network == "tcp4" || c.conn.network == "tcp6" || c.conn.network == "unix",
+			"conn.network must be one of tcp, tcp4, tcp6 or unix: '%s'", c.conn.network)
+		if !c.IsSentinel() && !c.IsCluster() {
+			if unify4g.IsEmpty(c.conn.connectionStrings) {
+				errs = append(errs, errors.New("conn.addr is required"))
+			} else if c.conn.network != "unix" {
+				check(isHostPort(c.conn.connectionStrings), "conn.addr must be in 'host:port' format: '%s'", c.conn.connectionStrings)
+			}
+		}
+		check(c.conn.database >= 0, "conn.database must not be negative: %d", c.conn.database)
+		check(!c.IsCluster() || c.conn.database == 0, "conn.database must be 0 in cluster mode: %d", c.conn.database)
+	}
+
+	if c.retry == nil {
+		errs = append(errs, errors.New("retry settings are required"))
+	} else {
+		check(c.retry.maxRetries >= -1, "retry.max_retries must be -1 (disabled) or greater: %d", c.retry.maxRetries)
+		check(isTimeout(c.retry.minRetryBackoff), "retry.min_retry_backoff must be -1 (disabled) or greater: %v", c.retry.minRetryBackoff)
+		check(isTimeout(c.retry.maxRetryBackoff), "retry.max_retry_backoff must be -1 (disabled) or greater: %v", c.retry.maxRetryBackoff)
+		check(c.retry.minRetryBackoff <= 0 || c.retry.maxRetryBackoff <= 0 || c.retry.minRetryBackoff <= c.retry.maxRetryBackoff,
+			"retry.min_retry_backoff (%v) must not exceed retry.max_retry_backoff (%v)", c.retry.minRetryBackoff, c.retry.maxRetryBackoff)
+	}
+
+	if c.timeout == nil {
+		errs = append(errs, errors.New("timeout settings are required"))
+	} else {
+		check(c.timeout.connTimeout >= 0, "timeout.dial_timeout must not be negative: %v", c.timeout.connTimeout)
+		check(isTimeout(c.timeout.readTimeout), "timeout.read_timeout must be -1 (no timeout) or greater: %v", c.timeout.readTimeout)
+		check(isTimeout(c.timeout.writeTimeout), "timeout.write_timeout must be -1 (no timeout) or greater: %v", c.timeout.writeTimeout)
+	}
+
+	if c.pool == nil {
+		errs = append(errs, errors.New("pool settings are required"))
+	} else {
+		check(c.pool.poolSize >= 0, "pool.pool_size must not be negative: %d", c.pool.poolSize)
+		check(c.pool.minIdleConn >= 0, "pool.min_idle_conns must not be negative: %d", c.pool.minIdleConn)
+		check(c.pool.poolSize <= 0 || c.pool.minIdleConn <= c.pool.poolSize,
+			"pool.min_idle_conns (%d) must not exceed pool.pool_size (%d)", c.pool.minIdleConn, c.pool.poolSize)
+		check(c.pool.maxConnAge >= 0, "pool.max_conn_age must not be negative: %v", c.pool.maxConnAge)
+		check(c.pool.poolTimeout >= 0, "pool.pool_timeout must not be negative: %v", c.pool.poolTimeout)
+		check(isTimeout(c.pool.idleTimeout), "pool.idle_timeout must be -1 (disabled) or greater: %v", c.pool.idleTimeout)
+		check(isTimeout(c.pool.idleCheckFrequency), "pool.idle_check_frequency must be -1 (disabled) or greater: %v", c.pool.idleCheckFrequency)
+	}
+
+	if c.IsSentinel() {
+		check(unify4g.IsNotEmpty(c.sentinel.masterName), "sentinel.master_name is required in sentinel mode")
+		check(len(c.sentinel.addrs) > 0, "sentinel.addrs requires at least one address in sentinel mode")
+		for _, addr := range c.sentinel.addrs {
+			check(isHostPort(addr), "sentinel.addrs must be in 'host:port' format: '%s'", addr)
+		}
+	}
+
+	if c.IsCluster() {
+		check(!c.IsSentinel(), "sentinel mode and cluster mode cannot be enabled together")
+		check(len(c.cluster.addrs) > 0, "cluster.addrs requires at least one seed address in cluster mode")
+		for _, addr := range c.cluster.addrs {
+			check(isHostPort(addr), "cluster.addrs must be in 'host:port' format: '%s'", addr)
+		}
+		check(c.cluster.maxRedirects >= -1, "cluster.max_redirects must be -1 (disabled) or greater: %d", c.cluster.maxRedirects)
+	}
+
+	if c.IsTLS() {
+		check(c.tls.minVersion >= tls.VersionTLS10 && c.tls.minVersion <= tls.VersionTLS13,
+			"tls.min_version is not a supported TLS version: %s", formatTLSVersion(c.tls.minVersion))
+		check(unify4g.IsEmpty(c.tls.certFile) == unify4g.IsEmpty(c.tls.keyFile),
+			"tls.cert_file and tls.key_file must be set together")
+		for _, file := range [][2]string{{"ca_file", c.tls.caFile}, {"cert_file", c.tls.certFile}, {"key_file", c.tls.keyFile}} {
+			if unify4g.IsEmpty(file[1]) {
+				continue
+			}
+			_, err := os.Stat(file[1])
+			check(err == nil, "tls.%s cannot be accessed: %v", file[0], err)
+		}
+	}
+
+	// Without reconnect settings, the default policy applies (see NewReconnectSettings).
+	if c.reconnect != nil {
+		check(c.reconnect.initialDelay >= 0, "reconnect.initial_delay must not be negative: %v", c.reconnect.initialDelay)
+		check(c.reconnect.multiplier >= 1, "reconnect.multiplier must be 1 or greater: %v", c.reconnect.multiplier)
+		check(c.reconnect.maxDelay >= 0, "reconnect.max_delay must not be negative: %v", c.reconnect.maxDelay)
+		check(c.reconnect.maxDelay <= 0 || c.reconnect.initialDelay <= c.reconnect.maxDelay,
+			"reconnect.initial_delay (%v) must not exceed reconnect.max_delay (%v)", c.reconnect.initialDelay, c.reconnect.maxDelay)
+		check(c.reconnect.jitter >= 0 && c.reconnect.jitter <= 1, "reconnect.jitter must be between 0 and 1: %v", c.reconnect.jitter)
+		check(c.reconnect.maxAttempts >= 0, "reconnect.max_attempts must not be negative: %d", c.reconnect.maxAttempts)
+		check(c.reconnect.giveUp == GiveUpResume || c.reconnect.giveUp == GiveUpStop || c.reconnect.giveUp == GiveUpClose,
+			"reconnect.give_up must be one of resume, stop or close: '%s'", c.reconnect.giveUp)
+	}
+
+	if c.IsTracking() {
+		check(!c.IsCluster(), "tracking is not supported in cluster mode")
+		check(c.tracking.mode == TrackingDefault || c.tracking.mode == TrackingBroadcast,
+			"tracking.mode must be one of default or broadcast: '%s'", c.tracking.mode)
+		check(c.tracking.mode == TrackingBroadcast || len(c.tracking.prefixes) == 0,
+			"tracking.prefixes requires the broadcast mode")
+	}
+	return errors.Join(errs...)
+}
+
+// isHostPort returns true if addr is in "host:port" format with a non-empty port.
+func isHostPort(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	return err == nil && unify4g.IsNotEmpty(port)
+}
+
+// isTimeout returns true if d is a valid go-redis duration option, i.e. not negative
+// unless it is -1, which disables the corresponding behaviour.
+func isTimeout(d time.Duration) bool {
+	return d >= -1
+}
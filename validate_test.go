@@ -0,0 +1,97 @@
+package redisc
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validSettings returns Settings passing Validate, which the test cases then break.
+func validSettings() *Settings {
+	s := NewSettings()
+	s.Conn().SetConnectionStrings("127.0.0.1:6379")
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(s *Settings)
+		want   []string
+	}{
+		{"defaults", func(s *Settings) {}, nil},
+		{"nil reconnect is the default policy", func(s *Settings) { s.reconnect = nil }, nil},
+		{"unix socket", func(s *Settings) {
+			s.conn.network = "unix"
+			s.conn.connectionStrings = "/var/run/redis.sock"
+		}, nil},
+		{"missing address", func(s *Settings) { s.conn.connectionStrings = "" }, []string{"conn.addr is required"}},
+		{"missing conn", func(s *Settings) { s.conn = nil }, []string{"conn settings are required"}},
+		{"invalid address", func(s *Settings) { s.conn.connectionStrings = "localhost" }, []string{"conn.addr must be in 'host:port' format"}},
+		{"several problems at once", func(s *Settings) {
+			s.conn.network = "udp"
+			s.pingInterval = -time.Second
+			s.pool.minIdleConn = s.pool.poolSize + 1
+		}, []string{
+			"ping_interval must not be negative",
+			"conn.network must be one of tcp, tcp4, tcp6 or unix",
+			"pool.min_idle_conns",
+		}},
+		{"reconnect policy", func(s *Settings) {
+			s.reconnect.SetMultiplier(0.5).SetJitter(2).SetGiveUp("retry").SetInitialDelay(time.Minute).SetMaxDelay(time.Second)
+		}, []string{
+			"reconnect.multiplier must be 1 or greater",
+			"reconnect.jitter must be between 0 and 1",
+			"reconnect.give_up must be one of resume, stop or close",
+			"reconnect.initial_delay (1m0s) must not exceed reconnect.max_delay (1s)",
+		}},
+		{"sentinel without master", func(s *Settings) {
+			s.SetSentinel(NewSentinelSettings().SetEnable(true).SetAddrs([]string{"sentinel"}))
+		}, []string{
+			"sentinel.master_name is required",
+			"sentinel.addrs must be in 'host:port' format: 'sentinel'",
+		}},
+		{"cluster with database", func(s *Settings) {
+			s.SetCluster(NewClusterSettings().SetEnable(true))
+			s.conn.database = 2
+		}, []string{
+			"conn.database must be 0 in cluster mode",
+			"cluster.addrs requires at least one seed address",
+		}},
+		{"tracking in cluster mode", func(s *Settings) {
+			s.SetCluster(NewClusterSettings().SetEnable(true).SetAddrs([]string{"127.0.0.1:7000"}))
+			s.SetTracking(NewTrackingSettings().SetEnable(true).SetPrefixes("user:"))
+		}, []string{
+			"tracking is not supported in cluster mode",
+			"tracking.prefixes requires the broadcast mode",
+		}},
+	}
+	for _, tt := range tests {
+		s := validSettings()
+		tt.mutate(s)
+		err := s.Validate()
+		if len(tt.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: Validate() error = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: Validate() error = nil, want %q", tt.name, tt.want)
+			continue
+		}
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Errorf("%s: Validate() error %T does not join the problems", tt.name, err)
+			continue
+		}
+		if got := len(joined.Unwrap()); got != len(tt.want) {
+			t.Errorf("%s: Validate() reported %d problems, want %d:\n%v", tt.name, got, len(tt.want), err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: Validate() error is missing %q:\n%v", tt.name, want, err)
+			}
+		}
+	}
+}